@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+var agentsCmd = &cobra.Command{
+	Use:   "agents",
+	Short: "Inspect agents",
+	Long:  "Commands to list and inspect the agent groups and per-node agents that report cluster data to Kaspersky Container Security.",
+}
+
+// agentColumns are the table columns for agents.
+var agentColumns = []output.Column[model.Agent]{
+	{Header: "Group", Value: func(a model.Agent) string { return a.AgentGroupName }},
+	{Header: "Node", Value: func(a model.Agent) string { return a.NodeName }},
+	{Header: "Type", Value: func(a model.Agent) string { return a.Type }},
+	{Header: "Version", Value: func(a model.Agent) string { return a.Version }},
+	{Header: "Status", Value: func(a model.Agent) string { return a.Status }},
+	{Header: "LastSeen", Value: func(a model.Agent) string {
+		age, ok := ctrl.HeartbeatAge(a.LastHeartbeat, time.Now())
+		if !ok {
+			return "never"
+		}
+		return ctrl.FormatAge(age) + " ago"
+	}},
+	{Header: "Heartbeat", Wide: true, Value: func(a model.Agent) string { return a.LastHeartbeat }},
+	{Header: "GroupID", Wide: true, Value: func(a model.Agent) string { return a.AgentGroupId }},
+	{Header: "ID", Wide: true, Value: func(a model.Agent) string { return a.ID }},
+}
+
+// flagAgentStale holds --stale; set without a value it uses ctrl.DefaultStaleAfter.
+var flagAgentStale string
+
+// addStaleFlag registers --stale[=<duration>] on c.
+func addStaleFlag(c *cobra.Command) {
+	c.Flags().StringVar(&flagAgentStale, "stale", "", "only show agents without a heartbeat for this long (default "+ctrl.DefaultStaleAfter.String()+" when given without a value)")
+	c.Flags().Lookup("stale").NoOptDefVal = ctrl.DefaultStaleAfter.String()
+}
+
+// staleAfter parses --stale and exits on an invalid duration; ok is false when --stale was not given.
+func staleAfter() (time.Duration, bool) {
+	if flagAgentStale == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(flagAgentStale)
+	if err != nil || d <= 0 {
+		fmt.Fprintf(os.Stderr, "error: invalid --stale %q (want a positive duration such as 10m)\n", flagAgentStale)
+		os.Exit(1)
+	}
+	return d, true
+}
+
+func init() {
+	rootCmd.AddCommand(agentsCmd)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+var agentsGetOutput outputFlags
+
+var agentsGetCmd = &cobra.Command{
+	Use:   "get <group|cluster>",
+	Short: "Show an agent group and its agents",
+	Long: `Show an agent group (by ID or name, or by the ID or name of the cluster it
+monitors) and the agents deployed per node: version, connection status and last
+heartbeat.
+
+The table starts with a summary of the group: cluster, connected agents and agent
+versions. json, yaml, jsonpath and go-template render the group with its agents.
+
+Examples:
+  kcskit agents get prod
+  kcskit agents get <agent-group-id> --stale=30m`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		after, stale := staleAfter()
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		group, body, err := ctrl.FindAgentGroup(cmd.Context(), cfg, InvalidCert, args[0])
+		if err != nil {
+			fmt.Println("failed to find agent group:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		detail, body, endpoint, err := ctrl.GetAgentGroup(cmd.Context(), cfg, InvalidCert, group.ID)
+		if err != nil {
+			fmt.Println("failed to get agent group:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		agents := detail.Agents
+		for i := range agents {
+			if agents[i].AgentGroupName == "" {
+				agents[i].AgentGroupName = detail.Name
+			}
+		}
+		if stale {
+			filtered := *detail
+			filtered.Agents = ctrl.StaleAgents(agents, after, time.Now())
+			agents = filtered.Agents
+			b, err := json.Marshal(filtered)
+			if err != nil {
+				fmt.Println("failed to encode agent group:", err)
+				os.Exit(1)
+			}
+			body = string(b)
+		}
+
+		if agentsGetOutput.isAI() {
+			sendAgentsToAI(cmd, cfg, body, endpoint, detail.ClusterName)
+			return
+		}
+
+		if agentsGetOutput.isTable() {
+			fmt.Printf("Group:      %s\n", detail.Name)
+			fmt.Printf("ID:         %s\n", detail.ID)
+			fmt.Printf("Cluster:    %s\n", detail.ClusterName)
+			fmt.Printf("Connected:  %d/%d\n", detail.ConnectedCount, detail.AgentCount)
+			fmt.Printf("Versions:   %s\n\n", ctrl.AgentVersions(detail.Agents))
+			if len(agents) == 0 {
+				if stale {
+					fmt.Printf("No agents without a heartbeat for more than %s.\n", after)
+				} else {
+					fmt.Println("No agents.")
+				}
+				return
+			}
+		}
+		printItems(&agentsGetOutput, agentColumns, agents, body)
+	},
+}
+
+func init() {
+	agentsCmd.AddCommand(agentsGetCmd)
+
+	addStaleFlag(agentsGetCmd)
+	addOutputFlags(agentsGetCmd, &agentsGetOutput, "ai")
+}
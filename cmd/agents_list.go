@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+// agentGroupColumns are the table columns for agents list --groups.
+var agentGroupColumns = []output.Column[model.AgentGroup]{
+	{Header: "ID", Value: func(g model.AgentGroup) string { return g.ID }},
+	{Header: "Name", Value: func(g model.AgentGroup) string { return g.Name }},
+	{Header: "Cluster", Value: func(g model.AgentGroup) string { return g.ClusterName }},
+	{Header: "Connected", Value: func(g model.AgentGroup) string {
+		return strconv.Itoa(g.ConnectedCount) + "/" + strconv.Itoa(g.AgentCount)
+	}},
+	{Header: "Orchestrator", Wide: true, Value: func(g model.AgentGroup) string { return g.Orchestrator }},
+}
+
+var (
+	agentsListOutput outputFlags
+	flagAgentGroup   string
+	flagAgentGroups  bool
+)
+
+var agentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List agents or agent groups",
+	Long: `List the agents deployed per node with their group, version, connection status
+and last heartbeat. --groups lists the agent groups instead, with their
+connected/total agent counts.
+
+--stale keeps only agents whose last heartbeat is older than the given duration
+(10m when no value is given) or that never reported.
+
+Examples:
+  kcskit agents list
+  kcskit agents list --group prod --stale
+  kcskit agents list --stale=1h -o wide
+  kcskit agents list --groups`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		after, stale := staleAfter()
+		if flagAgentGroups && (stale || flagAgentGroup != "") {
+			fmt.Fprintln(os.Stderr, "error: --groups cannot be combined with --stale or --group")
+			os.Exit(1)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		if flagAgentGroups {
+			groups, body, endpoint, err := ctrl.ListAgentGroups(cmd.Context(), cfg, InvalidCert)
+			if err != nil {
+				fmt.Println("failed to list agent groups:", err)
+				if body != "" {
+					fmt.Println("response body:", body)
+				}
+				os.Exit(1)
+			}
+			if agentsListOutput.isAI() {
+				sendAgentsToAI(cmd, cfg, body, endpoint, "")
+				return
+			}
+			printItems(&agentsListOutput, agentGroupColumns, groups, body)
+			return
+		}
+
+		var groupID, cluster string
+		if flagAgentGroup != "" {
+			group, body, err := ctrl.FindAgentGroup(cmd.Context(), cfg, InvalidCert, flagAgentGroup)
+			if err != nil {
+				fmt.Println("failed to find agent group:", err)
+				if body != "" {
+					fmt.Println("response body:", body)
+				}
+				os.Exit(1)
+			}
+			groupID, cluster = group.ID, group.ClusterName
+		}
+
+		agents, body, endpoint, err := ctrl.ListAgents(cmd.Context(), cfg, InvalidCert, groupID)
+		if err != nil {
+			fmt.Println("failed to list agents:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		if stale {
+			agents = ctrl.StaleAgents(agents, after, time.Now())
+			b, err := json.Marshal(model.AgentsResponse{Total: len(agents), Page: 1, Items: agents})
+			if err != nil {
+				fmt.Println("failed to encode agents:", err)
+				os.Exit(1)
+			}
+			body = string(b)
+		}
+
+		if agentsListOutput.isAI() {
+			sendAgentsToAI(cmd, cfg, body, endpoint, cluster)
+			return
+		}
+		if stale && len(agents) == 0 && agentsListOutput.isTable() {
+			fmt.Printf("No agents without a heartbeat for more than %s.\n", after)
+			return
+		}
+		printItems(&agentsListOutput, agentColumns, agents, body)
+	},
+}
+
+// sendAgentsToAI sends an agent report to the AI model and prints the answer.
+func sendAgentsToAI(cmd *cobra.Command, cfg model.Config, body, endpoint, cluster string) {
+	header := model.OllamaHeader{
+		Command:     strings.Join(os.Args, " "),
+		Cluster:     cluster,
+		Risk:        "",
+		ReportTitle: "Kaspersky Container Security Agent Status Report.",
+		ApiEndpoint: endpoint,
+	}
+	sendToAI(cmd, cfg, body, header)
+}
+
+func init() {
+	agentsCmd.AddCommand(agentsListCmd)
+
+	agentsListCmd.Flags().StringVar(&flagAgentGroup, "group", "", "only list agents of this agent group (ID or name, or the cluster it monitors)")
+	agentsListCmd.Flags().BoolVar(&flagAgentGroups, "groups", false, "list agent groups instead of agents")
+	addStaleFlag(agentsListCmd)
+	addOutputFlags(agentsListCmd, &agentsListOutput, "ai")
+}
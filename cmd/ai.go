@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var aiCmd = &cobra.Command{
+	Use:   "ai",
+	Short: "Work with the AI model",
+	Long:  "Commands to chat with the configured AI model (see 'kcskit config --ai-provider') about KCS results.",
+}
+
+func init() {
+	rootCmd.AddCommand(aiCmd)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+var (
+	flagChatInput  string
+	flagChatResume string
+	flagChatNoSave bool
+)
+
+const chatHelp = `Commands:
+  /help    show this help
+  /clear   forget the questions and answers, keep the command output
+  /save    save the transcript now and print its path
+  /exit    end the session (also /quit or Ctrl-D)`
+
+var aiChatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Chat with the AI model about KCS results",
+	Long: `Open an interactive chat session with the configured AI model.
+
+The session keeps the message history, so follow-up questions can refer to earlier
+answers. Data given with --input (e.g. the JSON output of a kcskit command) is kept
+as context for the whole session. Use -o ai --interactive on a command to chat about
+its results right after the report.
+
+Transcripts are saved as JSON to the "transcripts" directory next to the config file
+(default ~/.kcskit/transcripts) after every answer, and can be continued with --resume.
+
+` + chatHelp + `
+
+Examples:
+  kcskit images list -o json > images.json
+  kcskit ai chat --input images.json
+  kcskit images list -o ai --interactive
+  kcskit ai chat --resume ~/.kcskit/transcripts/chat-20250101-120000.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		provider, err := ctrl.NewAIProvider(cfg)
+		if err != nil {
+			fmt.Println("failed to start chat:", err)
+			os.Exit(1)
+		}
+
+		var session *ctrl.ChatSession
+		switch {
+		case flagChatResume != "":
+			session, err = ctrl.ResumeChatSession(provider, flagChatResume)
+			if err != nil {
+				fmt.Println("failed to load transcript:", err)
+				os.Exit(1)
+			}
+		case flagChatInput != "":
+			b, err := os.ReadFile(flagChatInput)
+			if err != nil {
+				fmt.Println("failed to read input:", err)
+				os.Exit(1)
+			}
+			stream := output.NewAnswerStream(os.Stdout, os.Stderr, provider.Model())
+			session, err = ctrl.NewChatSession(cmd.Context(), provider, "", string(b), stream)
+			stream.Close()
+			if err != nil {
+				fmt.Println("failed to start chat:", err)
+				os.Exit(1)
+			}
+		default:
+			session, _ = ctrl.NewChatSession(cmd.Context(), provider, "", "", nil)
+		}
+		runChat(cmd, session, !flagChatNoSave)
+	},
+}
+
+// runChat reads questions from stdin and streams the answers until /exit, EOF
+// or Ctrl-C. With save, the transcript is saved after every answer.
+func runChat(cmd *cobra.Command, session *ctrl.ChatSession, save bool) {
+	t := &session.Transcript
+	fmt.Fprintf(os.Stderr, "\nChatting with %s (%s). Type /help for commands, /exit or Ctrl-D to quit.\n", t.Model, t.Provider)
+	if n := session.Turns(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Resumed %d previous question(s).\n", n)
+	}
+
+	var saved string
+	saveTranscript := func() {
+		p, err := session.Save()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "warning:", err)
+			return
+		}
+		saved = p
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 64*1024), 1024*1024)
+loop:
+	for {
+		fmt.Fprint(os.Stderr, "\n>>> ")
+		if !in.Scan() {
+			fmt.Fprintln(os.Stderr)
+			break
+		}
+		line := strings.TrimSpace(in.Text())
+		switch {
+		case line == "":
+		case line == "/exit" || line == "/quit":
+			break loop
+		case line == "/help":
+			fmt.Fprintln(os.Stderr, chatHelp)
+		case line == "/clear":
+			session.Reset()
+			fmt.Fprintln(os.Stderr, "history cleared")
+		case line == "/save":
+			saveTranscript()
+			if saved != "" {
+				fmt.Fprintln(os.Stderr, "transcript saved to", saved)
+			}
+		case strings.HasPrefix(line, "/"):
+			fmt.Fprintf(os.Stderr, "unknown command %s (type /help)\n", line)
+		default:
+			stream := output.NewAnswerStream(os.Stdout, os.Stderr, t.Model)
+			answer, err := session.Ask(cmd.Context(), line, stream.WriteChunk)
+			if err != nil {
+				stream.Close()
+				fmt.Printf("failed to send to %s: %v\n", t.Provider, err)
+				if cmd.Context().Err() != nil {
+					break loop
+				}
+				continue
+			}
+			if err := stream.Finish(answer); err != nil {
+				fmt.Println("failed to print output:", err)
+			}
+			if session.Dropped > 0 {
+				fmt.Fprintf(os.Stderr, "(the %d oldest question(s) no longer fit in the context window and were left out)\n", session.Dropped)
+			}
+			if save {
+				saveTranscript()
+			}
+		}
+	}
+
+	if saved != "" {
+		fmt.Fprintln(os.Stderr, "transcript saved to", saved)
+	}
+}
+
+func init() {
+	aiCmd.AddCommand(aiChatCmd)
+
+	aiChatCmd.Flags().StringVar(&flagChatInput, "input", "", "file with data to chat about, e.g. the JSON output of a kcskit command")
+	aiChatCmd.Flags().StringVar(&flagChatResume, "resume", "", "continue a saved transcript")
+	aiChatCmd.Flags().BoolVar(&flagChatNoSave, "no-save", false, "do not save the transcript")
+	aiChatCmd.MarkFlagsMutuallyExclusive("input", "resume")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+// sendToAI sends body to the configured AI provider and streams the report to
+// stdout: tokens are printed as they arrive and rendered once the answer is
+// complete (raw when stdout is not a terminal). The prompt comes from the
+// command's template (or --prompt). With --interactive, a chat session about
+// the results follows the report. It exits on error.
+func sendToAI(cmd *cobra.Command, cfg model.Config, body string, header model.OllamaHeader) {
+	provider, err := ctrl.NewAIProvider(cfg)
+	if err != nil {
+		fmt.Println("failed to send to ai:", err)
+		os.Exit(1)
+	}
+	override, _ := cmd.Flags().GetString("prompt")
+	tmpl, err := ctrl.LoadPromptTemplate(ctrl.PromptName(cmd.CommandPath()), override)
+	if err != nil {
+		fmt.Println("failed to load prompt template:", err)
+		os.Exit(1)
+	}
+	stream := output.NewAnswerStream(os.Stdout, os.Stderr, provider.Model())
+	report, messages, err := ctrl.SendToAI(cmd.Context(), provider, tmpl, body, header, stream)
+	if err != nil {
+		stream.Close()
+		fmt.Printf("failed to send to %s: %v\n", provider.Name(), err)
+		os.Exit(1)
+	}
+	if err := stream.Finish(report); err != nil {
+		fmt.Println("failed to print output:", err)
+		os.Exit(1)
+	}
+
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		runChat(cmd, ctrl.NewReportChatSession(provider, header.Command, messages), true)
+	}
+}
+
+// aiReportAnnotation marks the commands that send their results to the AI model with -o ai.
+const aiReportAnnotation = "kcskit.ai-report"
+
+// markAIReport marks c as sending its results to the AI model with -o ai (it
+// is then listed by 'kcskit ai prompts list').
+func markAIReport(c *cobra.Command) {
+	if c.Annotations == nil {
+		c.Annotations = map[string]string{}
+	}
+	c.Annotations[aiReportAnnotation] = "true"
+}
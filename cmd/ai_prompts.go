@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+var aiPromptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Manage the prompt templates of AI reports",
+	Long: `Manage the prompt templates used by -o ai.
+
+Templates are Go text/template files in the "prompts" directory next to the config
+file (default ~/.kcskit/prompts). Each command uses <name>.tmpl (e.g. images-list.tmpl)
+on top of default.tmpl, on top of the built-in template, so a template only needs to
+define the blocks it changes: "system" (system prompt), "prompt" (the request) and
+"header" (the Markdown header of the report). 'kcskit ai prompts show default' prints
+the built-in template with the available fields and functions.
+
+Examples:
+  kcskit ai prompts list
+  kcskit ai prompts edit default        # house format and language for every report
+  kcskit ai prompts edit images-list
+  kcskit images list -o ai --prompt ./weekly.tmpl`,
+}
+
+var aiPromptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the prompt templates and the file each command uses",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		names := []string{ctrl.DefaultPromptName}
+		commands := map[string]string{ctrl.DefaultPromptName: "(all commands)"}
+		var walk func(c *cobra.Command)
+		walk = func(c *cobra.Command) {
+			if c.Annotations[aiReportAnnotation] != "" {
+				name := ctrl.PromptName(c.CommandPath())
+				names = append(names, name)
+				commands[name] = strings.TrimPrefix(c.CommandPath(), rootCmd.Name()+" ")
+			}
+			for _, sub := range c.Commands() {
+				walk(sub)
+			}
+		}
+		walk(rootCmd)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCOMMAND\tTEMPLATE")
+		for _, name := range names {
+			tmpl, err := ctrl.LoadPromptTemplate(name, "")
+			source := ""
+			if err != nil {
+				source = "error: " + err.Error()
+			} else {
+				source = tmpl.Sources[len(tmpl.Sources)-1]
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, commands[name], source)
+		}
+		_ = w.Flush()
+	},
+}
+
+var aiPromptsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the prompt template a command uses",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := ctrl.ValidatePromptName(args[0]); err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		text, source, err := ctrl.PromptText(args[0])
+		if err != nil {
+			fmt.Println("failed to read prompt template:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "# template:", source)
+		fmt.Print(text)
+	},
+}
+
+var aiPromptsEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Edit a prompt template in $VISUAL or $EDITOR",
+	Long: `Open the prompt template <name> in $VISUAL or $EDITOR (default vi). A missing
+template is created with a commented stub: define only the blocks to change, the others
+keep coming from default.tmpl and the built-in template. The template is checked after
+the editor exits.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := ctrl.ValidatePromptName(name); err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		path, err := ctrl.EnsurePromptFile(name)
+		if err != nil {
+			fmt.Println("failed to create prompt template:", err)
+			os.Exit(1)
+		}
+
+		editor := strings.Fields(os.Getenv("VISUAL"))
+		if len(editor) == 0 {
+			editor = strings.Fields(os.Getenv("EDITOR"))
+		}
+		if len(editor) == 0 {
+			editor = []string{"vi"}
+			if runtime.GOOS == "windows" {
+				editor = []string{"notepad"}
+			}
+		}
+		c := exec.Command(editor[0], append(editor[1:], path)...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Println("failed to run editor:", err)
+			os.Exit(1)
+		}
+
+		if _, err := ctrl.LoadPromptTemplate(name, ""); err != nil {
+			fmt.Printf("template %s is invalid: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Println("saved", path)
+	},
+}
+
+func init() {
+	aiCmd.AddCommand(aiPromptsCmd)
+	aiPromptsCmd.AddCommand(aiPromptsListCmd)
+	aiPromptsCmd.AddCommand(aiPromptsShowCmd)
+	aiPromptsCmd.AddCommand(aiPromptsEditCmd)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+// planColumns are the table columns for apply and diff plans.
+var planColumns = []output.Column[model.PlanAction]{
+	{Header: "Action", Value: func(a model.PlanAction) string { return a.Action }},
+	{Header: "Kind", Value: func(a model.PlanAction) string { return a.Kind }},
+	{Header: "Name", Value: func(a model.PlanAction) string { return a.Name }},
+	{Header: "ID", Value: func(a model.PlanAction) string { return a.ID }},
+	{Header: "Changes", Value: func(a model.PlanAction) string {
+		var fields []string
+		for _, c := range a.Changes {
+			fields = append(fields, c.Field)
+		}
+		return strings.Join(fields, ",")
+	}},
+	{Header: "Result", Value: func(a model.PlanAction) string { return a.Result }},
+}
+
+var (
+	applyOutput    outputFlags
+	flagApplyFile  string
+	flagApplyPrune bool
+	flagApplyDry   bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <dir|file|->",
+	Short: "Create, update and delete KCS resources to match YAML specs",
+	Long: `Apply makes the live KCS configuration match the resources declared in YAML
+documents (a file, a directory searched recursively for *.yaml/*.yml, or - for stdin).
+
+Resources are matched by kind and name. Missing resources are created and changed
+ones updated; resources that already match are left alone, so apply can be run
+repeatedly. With --prune, live resources of the kinds present in the specs that
+are not declared are deleted.
+
+Supported kinds: Registry (see 'kcskit registries create --help' for the spec).
+Credentials are write-only in the API: they are sent on create and update but
+never cause an update by themselves.
+
+Examples:
+  kcskit diff -f kcs/
+  kcskit apply -f kcs/ --prune`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, plan := planFromFlags(cmd, flagApplyFile, flagApplyPrune)
+
+		if flagApplyDry {
+			writePlan(os.Stdout, &applyOutput, plan)
+			return
+		}
+
+		failed := ctrl.ApplyPlan(cmd.Context(), cfg, InvalidCert, plan)
+		if applyOutput.isTable() {
+			for _, a := range plan {
+				result := a.Result
+				if result == "" {
+					result = a.Action
+				}
+				fmt.Printf("%s/%s %s\n", strings.ToLower(a.Kind), a.Name, result)
+			}
+		} else {
+			printItems(&applyOutput, planColumns, plan, "")
+		}
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "%d of %d changes failed\n", failed, len(plan))
+			os.Exit(1)
+		}
+	},
+}
+
+// planFromFlags loads the configuration, reads the specs in file and computes
+// the plan, exiting on errors.
+func planFromFlags(cmd *cobra.Command, file string, prune bool) (model.Config, []model.PlanAction) {
+	if file == "" {
+		fmt.Fprintln(os.Stderr, "error: -f is required")
+		_ = cmd.Help()
+		os.Exit(1)
+	}
+	set, err := ctrl.ReadResources(file)
+	if err != nil {
+		fmt.Println("failed to read specs:", err)
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Println("not configured:", err)
+		os.Exit(1)
+	}
+	if err := ctrl.ValidateConfig(cfg); err != nil {
+		fmt.Println("not configured:", err)
+		os.Exit(1)
+	}
+
+	plan, body, err := ctrl.Plan(cmd.Context(), cfg, InvalidCert, set, prune)
+	if err != nil {
+		fmt.Println("failed to compute plan:", err)
+		if body != "" {
+			fmt.Println("response body:", body)
+		}
+		os.Exit(1)
+	}
+	return cfg, plan
+}
+
+// writePlan prints plan as a diff (table output) or in the selected format.
+func writePlan(w io.Writer, f *outputFlags, plan []model.PlanAction) {
+	if !f.isTable() {
+		printItems(f, planColumns, plan, "")
+		return
+	}
+	for _, a := range plan {
+		ref := a.Kind + "/" + a.Name
+		switch a.Action {
+		case "create":
+			fmt.Fprintf(w, "+ %s\n", ref)
+		case "update":
+			fmt.Fprintf(w, "~ %s (id %s)\n", ref, a.ID)
+			for _, c := range a.Changes {
+				fmt.Fprintf(w, "    %s: %q -> %q\n", c.Field, c.From, c.To)
+			}
+		case "delete":
+			fmt.Fprintf(w, "- %s (id %s)\n", ref, a.ID)
+		}
+	}
+	fmt.Fprintf(w, "Plan: %s.\n", ctrl.SummarizePlan(plan))
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringVarP(&flagApplyFile, "filename", "f", "", "YAML file or directory with the resources to apply (- for stdin).")
+	applyCmd.Flags().BoolVar(&flagApplyPrune, "prune", false, "Delete live resources (of the kinds in the specs) that are not declared.")
+	applyCmd.Flags().BoolVar(&flagApplyDry, "dry-run", false, "Only print the plan, like 'kcskit diff'.")
+	addOutputFlags(applyCmd, &applyOutput)
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+// Exit codes of cicd gate.
+const (
+	gateExitFailed  = 2 // the scan violates the gate
+	gateExitTimeout = 3 // the scan did not appear or finish before --timeout
+)
+
+// gateDefaultTimeout bounds the wait of cicd gate when the global --timeout is not given.
+const gateDefaultTimeout = 30 * time.Minute
+
+var (
+	cicdGateOutput        outputFlags
+	flagGateScanID        string
+	flagGateBuildNumber   string
+	flagGateBuildPipeline string
+	flagGateArtifact      string
+	flagGateThreshold     string
+	flagGateFailOn        []string
+	flagGatePollInterval  time.Duration
+	flagGateSince         time.Duration
+)
+
+// gateColumns are the table columns for a quality gate result.
+var gateColumns = []output.Column[model.GateResult]{
+	{Header: "Result", Value: func(r model.GateResult) string {
+		if r.Passed {
+			return "PASSED"
+		}
+		return "FAILED"
+	}},
+	{Header: "Scan", Value: func(r model.GateResult) string { return r.ScanID }},
+	{Header: "Artifact", Value: func(r model.GateResult) string { return r.Artifact }},
+	{Header: "Status", Value: func(r model.GateResult) string { return r.Status }},
+	{Header: "Risk", Value: func(r model.GateResult) string { return r.RiskRating }},
+	{Header: "Threshold", Value: func(r model.GateResult) string { return r.Threshold }},
+	{Header: "Reasons", Value: func(r model.GateResult) string { return strings.Join(r.Reasons, "; ") }},
+	{Header: "Categories", Wide: true, Value: func(r model.GateResult) string {
+		var parts []string
+		for _, c := range ctrl.RiskCategories {
+			parts = append(parts, fmt.Sprintf("%s=%d", c, r.Categories[c]))
+		}
+		return strings.Join(parts, " ")
+	}},
+}
+
+var cicdGateCmd = &cobra.Command{
+	Use:   "gate",
+	Short: "Fail a pipeline when a CI/CD scan exceeds a risk threshold",
+	Long: `Find the CI/CD scan for a build (--build-pipeline with --build-number, optionally
+with --artifact) or a scan ID (--scan-id), wait until it finishes and evaluate it against
+a quality gate. Build numbers are only unique within a pipeline, and --build-pipeline or
+--artifact alone also match the scans of earlier builds, so any other combination needs
+--since to only accept scans created in that window before the gate started.
+
+The gate fails when the scan's risk rating is above --threshold or unknown, when any category
+given with --fail-on has findings, or when the scan itself failed.
+
+The gate waits at most 30 minutes for the scan to appear and finish; the global
+--timeout sets a different limit (0 waits forever).
+
+Exit codes: 0 passed, 1 error, 2 gate failed, 3 timed out.
+
+Examples:
+  kcskit cicd gate --build-pipeline my-app --build-number 42 --threshold medium
+  kcskit cicd gate --artifact registry.local/my-app:1.4.2 --since 10m --fail-on malware,sensitive-data --timeout 15m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if flagGateScanID == "" && flagGateBuildPipeline == "" && flagGateBuildNumber == "" && flagGateArtifact == "" {
+			fmt.Fprintln(os.Stderr, "error: one of --scan-id, --build-pipeline, --build-number or --artifact is required")
+			_ = cmd.Help()
+			os.Exit(1)
+		}
+		if flagGateScanID == "" && (flagGateBuildNumber == "" || flagGateBuildPipeline == "") && flagGateSince <= 0 {
+			if flagGateBuildNumber != "" {
+				fmt.Fprintln(os.Stderr, "error: --build-number is only unique within a pipeline; add --build-pipeline, or --since to only accept recent scans")
+			} else {
+				fmt.Fprintln(os.Stderr, "error: --build-pipeline and --artifact also match the scans of earlier builds; add --build-number with --build-pipeline, or --since to only accept recent scans")
+			}
+			os.Exit(1)
+		}
+		if !slices.Contains(ctrl.RiskLevels, flagGateThreshold) {
+			fmt.Fprintf(os.Stderr, "error: invalid --threshold %q (want %s)\n", flagGateThreshold, strings.Join(ctrl.RiskLevels, "|"))
+			os.Exit(1)
+		}
+		for _, c := range flagGateFailOn {
+			if !slices.Contains(ctrl.RiskCategories, c) {
+				fmt.Fprintf(os.Stderr, "error: invalid --fail-on category %q (want %s)\n", c, strings.Join(ctrl.RiskCategories, "|"))
+				os.Exit(1)
+			}
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		ctx := cmd.Context()
+		waitCtx := ctx
+		if !cmd.Flags().Changed("timeout") {
+			var cancel context.CancelFunc
+			waitCtx, cancel = context.WithTimeout(ctx, gateDefaultTimeout)
+			defer cancel()
+		}
+		start := time.Now()
+		var since time.Time
+		if flagGateSince > 0 {
+			since = start.Add(-flagGateSince)
+		}
+		var scan *model.CiCdScanDetail
+		detailed := false
+		lastStatus := ""
+
+		err = ctrl.Poll(waitCtx, flagGatePollInterval, func(ctx context.Context) (bool, error) {
+			if flagGateScanID != "" {
+				d, body, _, err := ctrl.GetCicdScan(ctx, cfg, InvalidCert, flagGateScanID)
+				if err != nil {
+					if body != "" {
+						return false, fmt.Errorf("%w (response body: %s)", err, body)
+					}
+					return false, err
+				}
+				scan, detailed = d, true
+			} else {
+				s, err := ctrl.FindCicdScan(ctx, cfg, InvalidCert, flagGateBuildNumber, flagGateBuildPipeline, flagGateArtifact, since)
+				if err != nil {
+					return false, err
+				}
+				if s == nil {
+					if lastStatus != "missing" {
+						fmt.Fprintf(os.Stderr, "waiting for the CI/CD scan to appear...\n")
+						lastStatus = "missing"
+					}
+					return false, nil
+				}
+				scan = &model.CiCdScanDetail{CiCdScan: *s}
+			}
+
+			if ctrl.IsTerminalStatus(scan.Status) {
+				return true, nil
+			}
+			if scan.Status != lastStatus {
+				fmt.Fprintf(os.Stderr, "scan %s (%s): %s [%s elapsed]\n", scan.ID, scan.ArtifactName, scan.Status, time.Since(start).Round(time.Second))
+				lastStatus = scan.Status
+			}
+			return false, nil
+		})
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				fmt.Fprintln(os.Stderr, "timed out waiting for the CI/CD scan to finish")
+				os.Exit(gateExitTimeout)
+			}
+			fmt.Println("failed to get CI/CD scan:", err)
+			os.Exit(1)
+		}
+
+		// category counts are only part of the scan detail
+		if len(flagGateFailOn) > 0 && !detailed {
+			d, body, _, err := ctrl.GetCicdScan(ctx, cfg, InvalidCert, scan.ID)
+			if err != nil {
+				fmt.Println("failed to get CI/CD scan details:", err)
+				if body != "" {
+					fmt.Println("response body:", body)
+				}
+				os.Exit(1)
+			}
+			scan = d
+		}
+
+		result := ctrl.EvaluateGate(*scan, flagGateThreshold, flagGateFailOn)
+		printItems(&cicdGateOutput, gateColumns, []model.GateResult{result}, "")
+		if !result.Passed {
+			os.Exit(gateExitFailed)
+		}
+	},
+}
+
+func init() {
+	cicdCmd.AddCommand(cicdGateCmd)
+
+	cicdGateCmd.Flags().StringVar(&flagGateScanID, "scan-id", "", "ID of the CI/CD scan to evaluate.")
+	cicdGateCmd.Flags().StringVar(&flagGateBuildPipeline, "build-pipeline", "", "Find the latest scan of this build pipeline.")
+	cicdGateCmd.Flags().StringVar(&flagGateBuildNumber, "build-number", "", "Find the latest scan of this build number (with --build-pipeline or --since).")
+	cicdGateCmd.Flags().StringVar(&flagGateArtifact, "artifact", "", "Find the latest scan of this artifact (name or name:tag).")
+	cicdGateCmd.Flags().StringVar(&flagGateThreshold, "threshold", "medium", "Highest acceptable risk rating ("+strings.Join(ctrl.RiskLevels, "|")+").")
+	cicdGateCmd.Flags().StringSliceVar(&flagGateFailOn, "fail-on", nil, "Fail when these risk categories have findings ("+strings.Join(ctrl.RiskCategories, "|")+") (repeatable).")
+	cicdGateCmd.Flags().DurationVar(&flagGatePollInterval, "poll-interval", 10*time.Second, "How often to poll the scan status.")
+	cicdGateCmd.Flags().DurationVar(&flagGateSince, "since", 0, "Only accept scans created at most this long before the gate started, e.g. 30m.")
+
+	addOutputFlags(cicdGateCmd, &cicdGateOutput)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+var cicdGetOutput outputFlags
+
+var cicdGetCmd = &cobra.Command{
+	Use:   "get <scan-id>",
+	Short: "Show the full result of a CI/CD scan",
+	Long: `Show the full result of one CI/CD scan: build pipeline and number, image digest,
+finding counts per category, the findings themselves (vulnerabilities, malware,
+sensitive data, misconfigurations) and the policy decisions that triggered.
+
+The table starts with the build metadata and policy decisions, then lists one
+finding per row. json, yaml, jsonpath and go-template render the full scan result;
+csv and tsv the findings; -o sarif exports the findings for code scanning.
+
+Examples:
+  kcskit cicd get <scan-id>
+  kcskit cicd get <scan-id> -o wide | tee -a build.log
+  kcskit cicd get <scan-id> -o jsonpath='{.policyDecisions[*].policyName}'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		scan, body, endpoint, err := ctrl.GetCicdScan(cmd.Context(), cfg, InvalidCert, args[0])
+		if err != nil {
+			fmt.Println("failed to get ci/cd scan:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		detail := ctrl.CicdImageDetail(*scan)
+		findings := ctrl.ImageFindings(detail)
+
+		if cicdGetOutput.is("sarif") {
+			printJSON(ctrl.ImageDetailSARIF(detail, Version))
+			return
+		}
+
+		if cicdGetOutput.isAI() {
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     "",
+				Risk:        scan.RiskRating,
+				ReportTitle: "Kaspersky Container Security CI/CD Assessment Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		if cicdGetOutput.isTable() {
+			printCicdSummary(*scan, findings)
+			if len(findings) == 0 {
+				fmt.Println("No findings.")
+				return
+			}
+		}
+		printItems(&cicdGetOutput, imageFindingColumns, findings, body)
+	},
+}
+
+// printCicdSummary prints the build metadata, finding counts and policy decisions of a scan.
+func printCicdSummary(scan model.CiCdScanDetail, findings []model.ImageFinding) {
+	fmt.Printf("Scan:      %s\n", scan.ID)
+	fmt.Printf("Artifact:  %s\n", scan.ArtifactName)
+	if scan.Digest != "" {
+		fmt.Printf("Digest:    %s\n", scan.Digest)
+	}
+	fmt.Printf("Pipeline:  %s\n", scan.BuildPipeline)
+	fmt.Printf("Build:     %s\n", scan.BuildNumber)
+	fmt.Printf("Status:    %s\n", scan.Status)
+	fmt.Printf("Risk:      %s\n", scan.RiskRating)
+	if !scan.CreatedAt.IsZero() {
+		fmt.Printf("Created:   %s\n", scan.CreatedAt.Format(time.RFC3339))
+	}
+	s := scan.RiskSummary
+	fmt.Printf("Findings:  %d vulnerabilities, %d malware, %d sensitive data, %d misconfigurations\n",
+		s.Vulnerabilities, s.Malware, s.SensitiveData, s.Misconfigurations)
+	fmt.Printf("Severity:  %s\n", ctrl.SeveritySummary(findings))
+
+	triggered := ctrl.TriggeredPolicies(scan)
+	fmt.Printf("Policies:  %d evaluated, %d triggered\n", len(scan.PolicyDecisions), len(triggered))
+	for _, d := range triggered {
+		name := d.PolicyName
+		if name == "" {
+			name = d.PolicyID
+		}
+		line := fmt.Sprintf("  - %s: %s", name, d.Result)
+		if d.Action != "" {
+			line += " (" + d.Action + ")"
+		}
+		if d.Reason != "" {
+			line += ": " + d.Reason
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+}
+
+func init() {
+	cicdCmd.AddCommand(cicdGetCmd)
+	addOutputFlags(cicdGetCmd, &cicdGetOutput, "sarif", "ai")
+}
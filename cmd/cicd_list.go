@@ -1,34 +1,43 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
-	"text/tabwriter"
+	"time"
 
 	ctrl "github.com/arturscheiner/kcskit/internal/controller"
 	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
 	"github.com/spf13/cobra"
 )
 
+// cicdColumns are the table columns for CI/CD scans.
+var cicdColumns = []output.Column[model.CiCdScan]{
+	{Header: "ID", Value: func(it model.CiCdScan) string { return it.ID }},
+	{Header: "Artifact", Value: func(it model.CiCdScan) string { return it.ArtifactName }},
+	{Header: "Risk", Value: func(it model.CiCdScan) string { return it.RiskRating }},
+	{Header: "Status", Value: func(it model.CiCdScan) string { return it.Status }},
+	{Header: "Created", Wide: true, Value: func(it model.CiCdScan) string { return it.CreatedAt.Format(time.RFC3339) }},
+}
+
 var (
-	cicdOutput            string
+	cicdOutput            outputFlags
 	flagCicdPage          int
 	flagCicdLimit         int
 	flagCicdSort          string
 	flagCicdBy            string
 	flagCicdBuildNumber   string
 	flagCicdBuildPipeline string
+	flagCicdAll           bool
 )
 
 var cicdListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List CI/CD scans",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := ctrl.LoadConfig()
+		cfg, err := loadConfig()
 		if err != nil {
 			return fmt.Errorf("not configured: %w", err)
 		}
@@ -39,7 +48,13 @@ var cicdListCmd = &cobra.Command{
 		page := strconv.Itoa(flagCicdPage)
 		limit := strconv.Itoa(flagCicdLimit)
 
-		items, body, endpoint, err := ctrl.ListCicd(cfg, InvalidCert, page, limit, flagCicdSort, flagCicdBy, flagCicdBuildNumber, flagCicdBuildPipeline)
+		var items *model.CiCdScansListResponse
+		var body, endpoint string
+		if flagCicdAll {
+			items, body, endpoint, err = ctrl.ListAllCicd(cmd.Context(), cfg, InvalidCert, flagCicdLimit, flagCicdSort, flagCicdBy, flagCicdBuildNumber, flagCicdBuildPipeline)
+		} else {
+			items, body, endpoint, err = ctrl.ListCicd(cmd.Context(), cfg, InvalidCert, page, limit, flagCicdSort, flagCicdBy, flagCicdBuildNumber, flagCicdBuildPipeline)
+		}
 		if err != nil {
 			fmt.Println("failed to list clusters:", err)
 			if body != "" {
@@ -48,15 +63,12 @@ var cicdListCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if cicdOutput == "json" {
-			var pretty bytes.Buffer
-			if err := json.Indent(&pretty, []byte(body), "", "  "); err != nil {
-				fmt.Println(body)
-			} else {
-				fmt.Println(pretty.String())
-			}
+		if cicdOutput.is("sarif") {
+			printJSON(ctrl.CicdSARIF(items.Items, Version))
 			return nil
-		} else if cicdOutput == "ollama" {
+		}
+
+		if cicdOutput.isAI() {
 			var risks []string
 			for _, item := range items.Items {
 				risks = append(risks, item.RiskRating)
@@ -69,21 +81,11 @@ var cicdListCmd = &cobra.Command{
 				ReportTitle: "Kaspersky Container Security CI/CD Assessment Report.",
 				ApiEndpoint: endpoint,
 			}
-			response, err := ctrl.SendToOllama(body, header)
-			if err != nil {
-				fmt.Println("failed to send to ollama:", err)
-				os.Exit(1)
-			}
-			fmt.Println(response)
+			sendToAI(cmd, cfg, body, header)
 			return nil
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tArtifact\tRisk\tStatus")
-		for _, it := range items.Items {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", it.ID, it.ArtifactName, it.RiskRating, it.Status)
-		}
-		_ = w.Flush()
+		printItems(&cicdOutput, cicdColumns, items.Items, body)
 		return nil
 	},
 }
@@ -97,6 +99,7 @@ func init() {
 	cicdListCmd.Flags().StringVar(&flagCicdBy, "by", "desc", "Sort by order (asc|desc)")
 	cicdListCmd.Flags().StringVar(&flagCicdBuildNumber, "build-number", "", "Filter by build number.")
 	cicdListCmd.Flags().StringVar(&flagCicdBuildPipeline, "build-pipeline", "", "Filter by build pipeline.")
+	cicdListCmd.Flags().BoolVar(&flagCicdAll, "all", false, "Fetch every page (uses --limit as page size, ignores --page).")
 
-	cicdListCmd.Flags().StringVarP(&cicdOutput, "output", "o", "", "output format (\"json\" for raw JSON output, \"ollama\" to send to Ollama). Default: tabbed table")
+	addOutputFlags(cicdListCmd, &cicdOutput, "sarif", "ai")
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+var clustersGetOutput outputFlags
+
+// clusterDetailColumns are the table columns for clusters get.
+var clusterDetailColumns = []output.Column[model.ClusterDetail]{
+	{Header: "ID", Value: func(it model.ClusterDetail) string { return it.ID }},
+	{Header: "Name", Value: func(it model.ClusterDetail) string { return it.ClusterName }},
+	{Header: "Orchestrator", Value: func(it model.ClusterDetail) string {
+		return strings.TrimSpace(it.Orchestrator + " " + it.OrchestratorVersion)
+	}},
+	{Header: "Nodes", Value: func(it model.ClusterDetail) string { return strconv.Itoa(it.Nodes) }},
+	{Header: "Namespaces", Value: func(it model.ClusterDetail) string { return strconv.Itoa(it.Namespaces) }},
+	{Header: "Workloads", Value: func(it model.ClusterDetail) string { return strconv.Itoa(it.Workloads) }},
+	{Header: "Risk", Value: func(it model.ClusterDetail) string { return it.RiskRating }},
+	{Header: "AgentGroup", Value: func(it model.ClusterDetail) string { return it.AgentGroupId }},
+	{Header: "AgentGroupName", Wide: true, Value: func(it model.ClusterDetail) string { return it.AgentGroupName }},
+	{Header: "Created", Wide: true, Value: func(it model.ClusterDetail) string { return it.CreatedAt }},
+}
+
+var clustersGetCmd = &cobra.Command{
+	Use:   "get <id|name>",
+	Short: "Show a cluster and its agent group",
+	Long: `Show one cluster: orchestrator, node, namespace and workload counts, risk rating
+and the agent group (AgentGroupId) that monitors it.
+
+Use "clusters namespaces" and "clusters workloads" to find out what makes a cluster risky.
+
+Examples:
+  kcskit clusters get prod
+  kcskit clusters get <cluster-id> -o wide`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		cluster := mustFindCluster(cmd, cfg, args[0])
+
+		detail, body, endpoint, err := ctrl.GetCluster(cmd.Context(), cfg, InvalidCert, cluster.ID)
+		if err != nil {
+			fmt.Println("failed to get cluster:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		if clustersGetOutput.isAI() {
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     detail.ClusterName,
+				Risk:        detail.RiskRating,
+				ReportTitle: "Kaspersky Container Security Cluster Assessment Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		printItems(&clustersGetOutput, clusterDetailColumns, []model.ClusterDetail{*detail}, body)
+	},
+}
+
+// mustFindCluster resolves a cluster ID or name or exits.
+func mustFindCluster(cmd *cobra.Command, cfg model.Config, ref string) *model.ClusterItem {
+	cluster, body, err := ctrl.FindCluster(cmd.Context(), cfg, InvalidCert, ref)
+	if err != nil {
+		fmt.Println("failed to find cluster:", err)
+		if body != "" {
+			fmt.Println("response body:", body)
+		}
+		os.Exit(1)
+	}
+	return cluster
+}
+
+func init() {
+	clustersCmd.AddCommand(clustersGetCmd)
+	addOutputFlags(clustersGetCmd, &clustersGetOutput, "ai")
+}
@@ -1,22 +1,30 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	ctrl "github.com/arturscheiner/kcskit/internal/controller"
 	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
 )
 
-var clustersOutput string
+var clustersOutput outputFlags
+
+// clusterColumns are the table columns for clusters.
+var clusterColumns = []output.Column[model.ClusterItem]{
+	{Header: "ID", Value: func(it model.ClusterItem) string { return it.ID }},
+	{Header: "Name", Value: func(it model.ClusterItem) string { return it.ClusterName }},
+	{Header: "Orchestrator", Value: func(it model.ClusterItem) string { return it.Orchestrator }},
+	{Header: "Namespaces", Value: func(it model.ClusterItem) string { return strconv.Itoa(it.Namespaces) }},
+	{Header: "Risk", Value: func(it model.ClusterItem) string { return it.RiskRating }},
+	{Header: "AgentGroup", Wide: true, Value: func(it model.ClusterItem) string { return it.AgentGroupId }},
+}
 
 var (
 	flagClusterPage   int
@@ -24,13 +32,14 @@ var (
 	flagClusterSort   string
 	flagClusterBy     string
 	flagClusterScopes []string
+	flagClusterAll    bool
 )
 
 var clustersListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List clusters",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := ctrl.LoadConfig()
+		cfg, err := loadConfig()
 		if err != nil {
 			fmt.Println("not configured:", err)
 			os.Exit(1)
@@ -52,9 +61,13 @@ var clustersListCmd = &cobra.Command{
 		for _, s := range flagClusterScopes {
 			v.Add("scopes[]", s)
 		}
-		rawQuery := v.Encode()
-
-		items, body, endpoint, err := ctrl.ListClusters(cfg, InvalidCert, rawQuery)
+		var items []model.ClusterItem
+		var body, endpoint string
+		if flagClusterAll {
+			items, body, endpoint, err = ctrl.ListAllClusters(cmd.Context(), cfg, InvalidCert, v, flagClusterLimit)
+		} else {
+			items, body, endpoint, err = ctrl.ListClusters(cmd.Context(), cfg, InvalidCert, v.Encode())
+		}
 		if err != nil {
 			fmt.Println("failed to list clusters:", err)
 			if body != "" {
@@ -63,15 +76,7 @@ var clustersListCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if clustersOutput == "json" {
-			var pretty bytes.Buffer
-			if err := json.Indent(&pretty, []byte(body), "", "  "); err != nil {
-				fmt.Println(body)
-			} else {
-				fmt.Println(pretty.String())
-			}
-			return
-		} else if clustersOutput == "ollama" {
+		if clustersOutput.isAI() {
 			var clusterNames []string
 			var risks []string
 			for _, item := range items {
@@ -86,21 +91,11 @@ var clustersListCmd = &cobra.Command{
 				ReportTitle: "Kaspersky Container Security Cluster Assessment Report.",
 				ApiEndpoint: endpoint,
 			}
-			response, err := ctrl.SendToOllama(body, header)
-			if err != nil {
-				fmt.Println("failed to send to ollama:", err)
-				os.Exit(1)
-			}
-			fmt.Println(response)
+			sendToAI(cmd, cfg, body, header)
 			return
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tName\tOrchestrator\tNamespaces\tRisk")
-		for _, it := range items {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", it.ID, it.ClusterName, it.Orchestrator, it.Namespaces, it.RiskRating)
-		}
-		_ = w.Flush()
+		printItems(&clustersOutput, clusterColumns, items, body)
 	},
 }
 
@@ -112,6 +107,7 @@ func init() {
 	clustersListCmd.Flags().StringVar(&flagClusterSort, "sort", "clusterName", "sort by (clusterName|orchestrator|namespaces|riskRating)")
 	clustersListCmd.Flags().StringVar(&flagClusterBy, "by", "asc", "sort order (asc|desc)")
 	clustersListCmd.Flags().StringSliceVar(&flagClusterScopes, "scopes", nil, "filter by scopes (repeatable)")
+	clustersListCmd.Flags().BoolVar(&flagClusterAll, "all", false, "fetch every page (uses --limit as page size, ignores --page)")
 
-	clustersListCmd.Flags().StringVarP(&clustersOutput, "output", "o", "", "output format (\"json\" for raw JSON output, \"ollama\" to send to Ollama). Default: tabbed table")
+	addOutputFlags(clustersListCmd, &clustersOutput, "ai")
 }
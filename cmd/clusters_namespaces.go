@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+var clustersNamespacesOutput outputFlags
+
+// namespaceColumns are the table columns for clusters namespaces.
+var namespaceColumns = []output.Column[model.NamespaceItem]{
+	{Header: "Namespace", Value: func(it model.NamespaceItem) string { return it.Name }},
+	{Header: "Workloads", Value: func(it model.NamespaceItem) string { return strconv.Itoa(it.Workloads) }},
+	{Header: "Images", Value: func(it model.NamespaceItem) string { return strconv.Itoa(it.Images) }},
+	{Header: "Risk", Value: func(it model.NamespaceItem) string { return it.RiskRating }},
+}
+
+var clustersNamespacesCmd = &cobra.Command{
+	Use:   "namespaces <cluster>",
+	Short: "List the namespaces of a cluster",
+	Long: `List every namespace of a cluster (ID or name) with its workload and image
+counts and risk rating.
+
+Examples:
+  kcskit clusters namespaces prod
+  kcskit clusters namespaces prod --sort-by .riskRating`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		cluster := mustFindCluster(cmd, cfg, args[0])
+
+		items, body, endpoint, err := ctrl.ListClusterNamespaces(cmd.Context(), cfg, InvalidCert, cluster.ID)
+		if err != nil {
+			fmt.Println("failed to list namespaces:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		if clustersNamespacesOutput.isAI() {
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     cluster.ClusterName,
+				Risk:        cluster.RiskRating,
+				ReportTitle: "Kaspersky Container Security Namespace Assessment Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		printItems(&clustersNamespacesOutput, namespaceColumns, items, body)
+	},
+}
+
+func init() {
+	clustersCmd.AddCommand(clustersNamespacesCmd)
+	addOutputFlags(clustersNamespacesCmd, &clustersNamespacesOutput, "ai")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+// workloadColumns are the table columns for clusters workloads.
+var workloadColumns = []output.Column[model.WorkloadItem]{
+	{Header: "Namespace", Value: func(it model.WorkloadItem) string { return it.Namespace }},
+	{Header: "Kind", Value: func(it model.WorkloadItem) string { return it.Kind }},
+	{Header: "Name", Value: func(it model.WorkloadItem) string { return it.Name }},
+	{Header: "Risk", Value: func(it model.WorkloadItem) string { return it.RiskRating }},
+	{Header: "Images", Value: func(it model.WorkloadItem) string {
+		names := make([]string, len(it.Images))
+		for i, img := range it.Images {
+			names[i] = img.Name
+		}
+		return strings.Join(names, ",")
+	}},
+	{Header: "ImageRisks", Wide: true, Value: func(it model.WorkloadItem) string {
+		risks := make([]string, len(it.Images))
+		for i, img := range it.Images {
+			risks[i] = img.RiskRating
+		}
+		return strings.Join(risks, ",")
+	}},
+	{Header: "Replicas", Wide: true, Value: func(it model.WorkloadItem) string { return strconv.Itoa(it.Replicas) }},
+	{Header: "ID", Wide: true, Value: func(it model.WorkloadItem) string { return it.ID }},
+}
+
+var (
+	clustersWorkloadsOutput outputFlags
+	flagWorkloadNamespace   string
+)
+
+var clustersWorkloadsCmd = &cobra.Command{
+	Use:   "workloads <cluster>",
+	Short: "List the running workloads of a cluster",
+	Long: `List the running workloads of a cluster (ID or name) with the images of their
+containers and risk ratings, highest risk first.
+
+Examples:
+  kcskit clusters workloads prod
+  kcskit clusters workloads prod --namespace payments -o wide
+  kcskit clusters workloads prod -o jsonpath='{range .items[*]}{.name}{"\t"}{.riskRating}{"\n"}{end}'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		cluster := mustFindCluster(cmd, cfg, args[0])
+
+		items, body, endpoint, err := ctrl.ListClusterWorkloads(cmd.Context(), cfg, InvalidCert, cluster.ID, flagWorkloadNamespace)
+		if err != nil {
+			fmt.Println("failed to list workloads:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		if clustersWorkloadsOutput.isAI() {
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     cluster.ClusterName,
+				Risk:        cluster.RiskRating,
+				ReportTitle: "Kaspersky Container Security Workload Assessment Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		printItems(&clustersWorkloadsOutput, workloadColumns, items, body)
+	},
+}
+
+func init() {
+	clustersCmd.AddCommand(clustersWorkloadsCmd)
+
+	clustersWorkloadsCmd.Flags().StringVarP(&flagWorkloadNamespace, "namespace", "n", "", "only list workloads of this namespace")
+	addOutputFlags(clustersWorkloadsCmd, &clustersWorkloadsOutput, "ai")
+}
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
 )
 
 var tokenFlag string
@@ -18,12 +21,28 @@ var endpointFlag string
 var caCertFlag string
 var aiOllamaEndpointFlag string
 var aiOllamaModelFlag string
+var aiProviderFlag string
+var aiOllamaApiKeyFlag string
+var aiOpenAIEndpointFlag string
+var aiOpenAIModelFlag string
+var aiOpenAIApiKeyFlag string
+var aiOpenAIAuthHeaderFlag string
+var aiContextLengthFlag int
+var secretBackendFlag string
+var tokenCommandFlag string
+
+// retryFlagNames are the global HTTP timeout and retry flags; given to config,
+// they are saved into the config instead of only overriding it.
+var retryFlagNames = []string{"http-timeout", "retries", "retry-backoff", "retry-max-backoff", "retry-non-idempotent"}
 
 // configCmd represents the config command
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage kcskit configuration (save token/endpoint)",
-	Long: `Save token and endpoint into $HOME/.kcskit/config
+	Long: `Save token and endpoint into $HOME/.kcskit/config (or the file given by --config / $KCSKIT_CONFIG)
+
+Settings are saved into the current context, or into the context named by --context
+(created if it does not exist). See 'kcskit config get-contexts' and 'kcskit config use-context'.
 
 CA certificate examples (ca_cert flag accepts literal PEM, a file path, or '-' to read from stdin):
 
@@ -36,10 +55,39 @@ cat /path/to/ca.pem | kcskit config --ca_cert - --endpoint https://kcs.example.c
 # Provide PEM inline (shell-escaped)
 kcskit config --ca_cert "$(cat /path/to/ca.pem)" --endpoint https://kcs.example.com/api/ --token kcs_...
 
-The ca_cert value is stored as the 'ca_cert' field in the YAML config at $HOME/.kcskit/config.`,
+The ca_cert value is stored as the 'ca_cert' field in the YAML config at $HOME/.kcskit/config.
+
+Keeping the token out of the config file:
+
+# Store the token in the OS keyring (Secret Service, Keychain, Credential Manager);
+# an existing plaintext token is migrated when only --secret-backend is given
+kcskit config --secret-backend keyring
+
+# Store the token in a passphrase-encrypted age file next to the config
+# (passphrase from $KCSKIT_SECRET_PASSPHRASE or prompted on the terminal)
+kcskit config --secret-backend age-file --token kcs_...
+
+# Read the token from an external command such as pass
+kcskit config --token-command "pass show kcs/prod"
+
+AI backend for -o ai (Ollama, or any OpenAI-compatible chat completions API such as
+vLLM, LM Studio, llama.cpp server or LocalAI):
+
+kcskit config --ai-provider ollama --ai-ollama-endpoint http://localhost:11434 --ai-ollama-model llama3.1
+kcskit config --ai-provider openai --ai-openai-endpoint http://localhost:8000/v1 --ai-openai-model qwen2.5 --ai-openai-api-key sk-...
+kcskit config --ai-context-length 16384   # cap the context window large results are chunked for
+
+HTTP timeout and retry policy: the global --http-timeout, --retries, --retry-backoff,
+--retry-max-backoff and --retry-non-idempotent flags are saved when given to config:
+
+kcskit config --http-timeout 15s --retries 5 --retry-backoff 1s --retry-max-backoff 30s`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// If no flags provided, show help
-		if tokenFlag == "" && endpointFlag == "" && caCertFlag == "" && aiOllamaEndpointFlag == "" && aiOllamaModelFlag == "" {
+		if tokenFlag == "" && endpointFlag == "" && caCertFlag == "" && aiOllamaEndpointFlag == "" && aiOllamaModelFlag == "" &&
+			aiProviderFlag == "" && aiOllamaApiKeyFlag == "" && aiOpenAIEndpointFlag == "" && aiOpenAIModelFlag == "" &&
+			aiOpenAIApiKeyFlag == "" && aiOpenAIAuthHeaderFlag == "" && aiContextLengthFlag == 0 &&
+			secretBackendFlag == "" && tokenCommandFlag == "" &&
+			!slices.ContainsFunc(retryFlagNames, cmd.Flags().Changed) {
 			_ = cmd.Help()
 			return
 		}
@@ -65,7 +113,48 @@ The ca_cert value is stored as the 'ca_cert' field in the YAML config at $HOME/.
 			// otherwise: treat caCertFlag as the literal PEM text (backwards compatible)
 		}
 
-		if err := ctrl.SaveConfig(tokenFlag, endpointFlag, caCertContent, aiOllamaEndpointFlag, aiOllamaModelFlag); err != nil {
+		toSave := model.Config{
+			Token:              tokenFlag,
+			Endpoint:           endpointFlag,
+			CaCert:             caCertContent,
+			AiOllamaEndpoint:   aiOllamaEndpointFlag,
+			AiOllamaModel:      aiOllamaModelFlag,
+			AiProvider:         aiProviderFlag,
+			AiOllamaApiKey:     aiOllamaApiKeyFlag,
+			AiOpenAIEndpoint:   aiOpenAIEndpointFlag,
+			AiOpenAIModel:      aiOpenAIModelFlag,
+			AiOpenAIApiKey:     aiOpenAIApiKeyFlag,
+			HTTPTimeout:        globalHTTPTimeout,
+			RetryMaxAttempts:   globalRetries,
+			RetryBackoff:       globalRetryBackoff,
+			RetryMaxBackoff:    globalRetryMaxBackoff,
+			AiOpenAIAuthHeader: aiOpenAIAuthHeaderFlag,
+			AiContextLength:    aiContextLengthFlag,
+		}
+		if aiProviderFlag != "" && !slices.Contains(ctrl.AIProviders, aiProviderFlag) {
+			fmt.Printf("error: unknown ai provider %q (want %s)\n", aiProviderFlag, strings.Join(ctrl.AIProviders, "|"))
+			return
+		}
+		if cmd.Flags().Changed("retry-non-idempotent") {
+			toSave.RetryNonIdempotent = &globalRetryNonIdempotent
+		}
+		if tokenCommandFlag != "" {
+			if tokenFlag != "" {
+				fmt.Println("error: --token and --token-command are mutually exclusive")
+				return
+			}
+			toSave.TokenRef = "exec:" + tokenCommandFlag
+			toSave.SecretBackend = "exec"
+		}
+		if secretBackendFlag != "" {
+			if !ctrl.ValidSecretBackend(secretBackendFlag) {
+				fmt.Println("error: unknown secret backend:", secretBackendFlag)
+				return
+			}
+			toSave.SecretBackend = secretBackendFlag
+		}
+
+		if err := ctrl.SaveConfig(globalContext, toSave); err != nil {
 			fmt.Println("error writing config file:", err)
 		} else {
 			fmt.Println("configuration saved")
@@ -80,5 +169,14 @@ func init() {
 	configCmd.Flags().StringVar(&caCertFlag, "ca_cert", "", "CA certificate PEM text or path to a PEM file. Use '-' to read from stdin.")
 	configCmd.Flags().StringVar(&aiOllamaEndpointFlag, "ai-ollama-endpoint", "", "the Ollama API endpoint URL")
 	configCmd.Flags().StringVar(&aiOllamaModelFlag, "ai-ollama-model", "", "the Ollama model name")
+	configCmd.Flags().StringVar(&aiProviderFlag, "ai-provider", "", "the AI backend used by -o ai (ollama|openai); default: the configured one")
+	configCmd.Flags().StringVar(&aiOllamaApiKeyFlag, "ai-ollama-api-key", "", "bearer token sent to Ollama (for an authenticating proxy)")
+	configCmd.Flags().StringVar(&aiOpenAIEndpointFlag, "ai-openai-endpoint", "", "the base URL of an OpenAI-compatible API, e.g. http://localhost:8000/v1")
+	configCmd.Flags().StringVar(&aiOpenAIModelFlag, "ai-openai-model", "", "the model name for the OpenAI-compatible API")
+	configCmd.Flags().StringVar(&aiOpenAIApiKeyFlag, "ai-openai-api-key", "", "the API key for the OpenAI-compatible API")
+	configCmd.Flags().StringVar(&aiOpenAIAuthHeaderFlag, "ai-openai-auth-header", "", "header carrying the OpenAI API key (default Authorization: Bearer <key>; e.g. api-key sends the raw key)")
+	configCmd.Flags().IntVar(&aiContextLengthFlag, "ai-context-length", 0, "context window in tokens AI reports are planned for (default: the model's window from Ollama, else 8192)")
+	configCmd.Flags().StringVar(&secretBackendFlag, "secret-backend", "", "store the token and AI API keys in a secret backend instead of the config file (keyring|age-file); migrates existing plaintext secrets")
+	configCmd.Flags().StringVar(&tokenCommandFlag, "token-command", "", "read the token from the output of a command, e.g. \"pass show kcs/prod\"")
 	rootCmd.AddCommand(configCmd)
 }
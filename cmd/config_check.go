@@ -1,26 +1,34 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	ctrl "github.com/arturscheiner/kcskit/internal/controller"
 	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
 )
 
 var invalidCert bool
-var outputFlag string
+var checkOutput outputFlags
+
+// healthColumns are the table columns for KCS component health.
+var healthColumns = []output.Column[model.HealthItem]{
+	{Header: "Name", Value: func(it model.HealthItem) string { return it.ComponentName }},
+	{Header: "Pod", Value: func(it model.HealthItem) string { return it.PodName }},
+	{Header: "Status", Value: func(it model.HealthItem) string { return it.Status }},
+	{Header: "Version", Value: func(it model.HealthItem) string { return it.Version }},
+	{Header: "Error", Value: func(it model.HealthItem) string { return it.ErrorMessage }},
+}
 
 var checkCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check if kcskit is configured and test connection to endpoint",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := ctrl.LoadConfig()
+		cfg, err := loadConfig()
 		if err != nil {
 			fmt.Println("not configured:", err)
 			os.Exit(1)
@@ -30,7 +38,7 @@ var checkCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		body, err := ctrl.TestConfigConnection(cfg, invalidCert)
+		body, err := ctrl.TestConfigConnection(cmd.Context(), cfg, invalidCert)
 		if err != nil {
 			fmt.Println("connection test failed:", err)
 			if body != "" {
@@ -39,18 +47,6 @@ var checkCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// if user requested JSON output, print pretty JSON and exit
-		if outputFlag == "json" {
-			var pretty bytes.Buffer
-			if err := json.Indent(&pretty, []byte(body), "", "  "); err != nil {
-				// fallback to raw body
-				fmt.Println(body)
-			} else {
-				fmt.Println(pretty.String())
-			}
-			return
-		}
-
 		var hr model.HealthResponse
 		if err := json.Unmarshal([]byte(body), &hr); err != nil {
 			fmt.Println("failed to parse health JSON:", err)
@@ -58,18 +54,12 @@ var checkCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// print tabbed table: Name | Pod | Status | Version | Error
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "Name\tPod\tStatus\tVersion\tError")
-		for _, it := range hr.Items {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", it.ComponentName, it.PodName, it.Status, it.Version, it.ErrorMessage)
-		}
-		_ = w.Flush()
+		printItems(&checkOutput, healthColumns, hr.Items, body)
 	},
 }
 
 func init() {
 	configCmd.AddCommand(checkCmd)
 	checkCmd.Flags().BoolVarP(&invalidCert, "invalid-cert", "i", false, "ignore TLS certificate validation when performing connection test")
-	checkCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "output format (\"json\" for raw JSON output). Default: tabbed table")
+	addOutputFlags(checkCmd, &checkOutput)
 }
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+var getContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List configuration contexts (profiles)",
+	Run: func(cmd *cobra.Command, args []string) {
+		contexts, current, err := ctrl.ListContexts()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		// print tabbed table: Current | Name | Endpoint
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Current\tName\tEndpoint")
+		for _, c := range contexts {
+			marker := ""
+			if c.Name == current {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", marker, c.Name, c.Endpoint)
+		}
+		_ = w.Flush()
+	},
+}
+
+var useContextCmd = &cobra.Command{
+	Use:   "use-context <name>",
+	Short: "Set the current configuration context",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := ctrl.UseContext(args[0]); err != nil {
+			fmt.Println("error switching context:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("switched to context %q\n", args[0])
+	},
+}
+
+var renameContextCmd = &cobra.Command{
+	Use:   "rename-context <old-name> <new-name>",
+	Short: "Rename a configuration context",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := ctrl.RenameContext(args[0], args[1]); err != nil {
+			fmt.Println("error renaming context:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("context %q renamed to %q\n", args[0], args[1])
+	},
+}
+
+var deleteContextCmd = &cobra.Command{
+	Use:   "delete-context <name>",
+	Short: "Delete a configuration context",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		warnings, err := ctrl.DeleteContext(args[0])
+		if err != nil {
+			fmt.Println("error deleting context:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("context %q deleted\n", args[0])
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, "warning:", w)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(getContextsCmd)
+	configCmd.AddCommand(useContextCmd)
+	configCmd.AddCommand(renameContextCmd)
+	configCmd.AddCommand(deleteContextCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// loadConfig returns the effective configuration: the selected context (--context,
+// $KCSKIT_CONTEXT or the current one) from the config file, overridden by KCSKIT_*
+// environment variables, overridden in turn by global flags.
+func loadConfig() (model.Config, error) {
+	cfg, _, err := loadConfigWithSources()
+	return cfg, err
+}
+
+// loadConfigWithSources is loadConfig that also reports, per config key, where the
+// effective value came from.
+func loadConfigWithSources() (model.Config, map[string]string, error) {
+	cfg, sources, err := ctrl.ResolveConfig(globalContext)
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	flags := rootCmd.PersistentFlags()
+	if flags.Changed("http-timeout") {
+		cfg.HTTPTimeout = globalHTTPTimeout
+		sources["http_timeout"] = "flag --http-timeout"
+	}
+	if flags.Changed("retries") {
+		cfg.RetryMaxAttempts = globalRetries
+		sources["retry_max_attempts"] = "flag --retries"
+	}
+	if flags.Changed("retry-backoff") {
+		cfg.RetryBackoff = globalRetryBackoff
+		sources["retry_backoff"] = "flag --retry-backoff"
+	}
+	if flags.Changed("retry-max-backoff") {
+		cfg.RetryMaxBackoff = globalRetryMaxBackoff
+		sources["retry_max_backoff"] = "flag --retry-max-backoff"
+	}
+	if flags.Changed("retry-non-idempotent") {
+		cfg.RetryNonIdempotent = &globalRetryNonIdempotent
+		sources["retry_non_idempotent"] = "flag --retry-non-idempotent"
+	}
+	return cfg, sources, nil
+}
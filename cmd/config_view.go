@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+var viewShowSource bool
+var viewRaw bool
+
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Show the effective configuration (file, environment and flags combined)",
+	Long: `Show the effective configuration for this invocation.
+
+Values are resolved with the precedence: global flags > KCSKIT_* environment variables > config file.
+Secrets are redacted unless --raw is given. A token or AI API key kept in a secret
+backend is shown as its *_ref; --raw reads it from the backend. Use --show-source to see where each value came from.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, sources, err := loadConfigWithSources()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		// secrets are only read from their backends to show them with --raw
+		if viewRaw {
+			if err := ctrl.ResolveConfigSecrets(&cfg, sources); err != nil {
+				fmt.Println("error:", err)
+				os.Exit(1)
+			}
+		}
+
+		if !viewRaw {
+			if cfg.Token != "" {
+				cfg.Token = "REDACTED"
+			}
+			for _, key := range []*string{&cfg.AiOllamaApiKey, &cfg.AiOpenAIApiKey} {
+				if *key != "" {
+					*key = "REDACTED"
+				}
+			}
+			if cfg.CaCert != "" {
+				cfg.CaCert = fmt.Sprintf("DATA+OMITTED (%d bytes)", len(cfg.CaCert))
+			}
+		}
+
+		if viewShowSource {
+			// print tabbed table: Key | Value | Source
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Key\tValue\tSource")
+			for _, v := range ctrl.DescribeConfig(cfg, sources) {
+				if v.Value == "" {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Value, v.Source)
+			}
+			_ = w.Flush()
+			return
+		}
+
+		out, err := yaml.Marshal(&cfg)
+		if err != nil {
+			fmt.Println("failed to render config:", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(out))
+	},
+}
+
+func init() {
+	configCmd.AddCommand(viewCmd)
+	viewCmd.Flags().BoolVar(&viewShowSource, "show-source", false, "show where each effective value came from (flag, env or file)")
+	viewCmd.Flags().BoolVar(&viewRaw, "raw", false, "show secrets (token, AI API keys, ca_cert) instead of redacting them")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+// diffExitChanged is the exit code of diff when the live configuration differs from the specs.
+const diffExitChanged = 2
+
+var (
+	diffOutput    outputFlags
+	flagDiffFile  string
+	flagDiffPrune bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff -f <dir|file|->",
+	Short: "Show what apply would change",
+	Long: `Diff compares the resources declared in YAML documents with the live KCS
+configuration and prints the create (+), update (~) and delete (-) plan that
+'kcskit apply' would execute. Nothing is changed.
+
+Exit codes: 0 no changes, 1 error, 2 changes pending.
+
+Examples:
+  kcskit diff -f kcs/
+  kcskit diff -f kcs/ --prune -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, plan := planFromFlags(cmd, flagDiffFile, flagDiffPrune)
+		writePlan(os.Stdout, &diffOutput, plan)
+		if ctrl.PlanChanged(plan) {
+			os.Exit(diffExitChanged)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVarP(&flagDiffFile, "filename", "f", "", "YAML file or directory with the desired resources (- for stdin).")
+	diffCmd.Flags().BoolVar(&flagDiffPrune, "prune", false, "Also show live resources (of the kinds in the specs) that apply --prune would delete.")
+	addOutputFlags(diffCmd, &diffOutput)
+}
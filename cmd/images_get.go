@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+// imageFindingColumns are the table columns for the findings of images get.
+var imageFindingColumns = []output.Column[model.ImageFinding]{
+	{Header: "Category", Value: func(f model.ImageFinding) string { return f.Category }},
+	{Header: "ID", Value: func(f model.ImageFinding) string { return f.ID }},
+	{Header: "Severity", Value: func(f model.ImageFinding) string { return f.Severity }},
+	{Header: "Package", Value: func(f model.ImageFinding) string { return f.Package }},
+	{Header: "Installed", Value: func(f model.ImageFinding) string { return f.Installed }},
+	{Header: "Fixed", Value: func(f model.ImageFinding) string { return f.Fixed }},
+	{Header: "Path", Wide: true, Value: func(f model.ImageFinding) string { return f.Path }},
+	{Header: "Title", Wide: true, Value: func(f model.ImageFinding) string { return f.Title }},
+}
+
+var (
+	imagesGetOutput      outputFlags
+	flagImageSeverity    []string
+	flagImageFixableOnly bool
+)
+
+var imagesGetCmd = &cobra.Command{
+	Use:   "get <id|name:tag>",
+	Short: "Show the scan result of an image",
+	Long: `Show the full scan result of one image: vulnerabilities (CVE, severity, package,
+installed and fixed version), malware, sensitive data and misconfigurations.
+
+An image name whose first segment is the host, name or ID of a registry (as in
+registry.local/app:1.4.2) is looked up among that registry's images only, which is
+faster than searching every scanned image.
+
+The table lists one finding per row after a short summary. json, yaml, jsonpath and
+go-template render the (filtered) scan result; -o sarif exports the findings for
+code scanning.
+
+Examples:
+  kcskit images get registry.local/app:1.4.2
+  kcskit images get <image-id> --severity critical,high --fixable-only
+  kcskit images get registry.local/app:1.4.2 -o sarif > app.sarif`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, s := range flagImageSeverity {
+			if !slices.Contains(ctrl.Severities, strings.ToLower(s)) {
+				fmt.Fprintf(os.Stderr, "error: invalid --severity %q (want %s)\n", s, strings.Join(ctrl.Severities, "|"))
+				os.Exit(1)
+			}
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		id, body, err := ctrl.ResolveImageID(cmd.Context(), cfg, InvalidCert, args[0])
+		if err != nil {
+			fmt.Println("failed to find image:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		detail, body, endpoint, err := ctrl.GetImage(cmd.Context(), cfg, InvalidCert, id)
+		if err != nil {
+			fmt.Println("failed to get image:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		filtered := *detail
+		if len(flagImageSeverity) > 0 || flagImageFixableOnly {
+			filtered = ctrl.FilterImageDetail(*detail, flagImageSeverity, flagImageFixableOnly)
+			b, err := json.Marshal(filtered)
+			if err != nil {
+				fmt.Println("failed to encode image:", err)
+				os.Exit(1)
+			}
+			body = string(b)
+		}
+		findings := ctrl.ImageFindings(filtered)
+
+		if imagesGetOutput.is("sarif") {
+			printJSON(ctrl.ImageDetailSARIF(filtered, Version))
+			return
+		}
+
+		if imagesGetOutput.isAI() {
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     "",
+				Risk:        filtered.RiskRating,
+				ReportTitle: "Kaspersky Container Security Image Assessment Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		if imagesGetOutput.isTable() {
+			fmt.Printf("Image:     %s\n", filtered.Name)
+			fmt.Printf("ID:        %s\n", filtered.ID)
+			fmt.Printf("Registry:  %s\n", filtered.ImageRegistryName)
+			fmt.Printf("Risk:      %s\n", filtered.RiskRating)
+			if filtered.Digest != "" {
+				fmt.Printf("Digest:    %s\n", filtered.Digest)
+			}
+			if filtered.ScannedAt != "" {
+				fmt.Printf("Scanned:   %s\n", filtered.ScannedAt)
+			}
+			fmt.Printf("Findings:  %s\n\n", ctrl.SeveritySummary(findings))
+			if len(findings) == 0 {
+				fmt.Println("No findings.")
+				return
+			}
+		}
+		printItems(&imagesGetOutput, imageFindingColumns, findings, body)
+	},
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesGetCmd)
+
+	imagesGetCmd.Flags().StringSliceVar(&flagImageSeverity, "severity", nil, "Only show findings with these severities ("+strings.Join(ctrl.Severities, "|")+") (repeatable).")
+	imagesGetCmd.Flags().BoolVar(&flagImageFixableOnly, "fixable-only", false, "Only show vulnerabilities that have a fixed version.")
+	addOutputFlags(imagesGetCmd, &imagesGetOutput, "sarif", "ai")
+}
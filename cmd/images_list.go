@@ -1,22 +1,33 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	ctrl "github.com/arturscheiner/kcskit/internal/controller"
 	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
 )
 
-var imagesOutput string
+var imagesOutput outputFlags
+
+// imageColumns are the table columns for images.
+var imageColumns = []output.Column[model.ImageItem]{
+	{Header: "ID", Value: func(it model.ImageItem) string { return it.ID }},
+	{Header: "Name", Value: func(it model.ImageItem) string { return it.Name }},
+	{Header: "Registry", Value: func(it model.ImageItem) string { return it.ImageRegistryName }},
+	{Header: "Risk", Value: func(it model.ImageItem) string { return it.RiskRating }},
+	{Header: "NonCompliant", Wide: true, Value: func(it model.ImageItem) string { return strconv.Itoa(it.NonCompliant) }},
+	{Header: "Total", Wide: true, Value: func(it model.ImageItem) string { return strconv.Itoa(it.Total) }},
+	{Header: "Errors", Wide: true, Value: func(it model.ImageItem) string { return strconv.Itoa(it.Errors) }},
+	{Header: "Process", Wide: true, Value: func(it model.ImageItem) string { return strconv.Itoa(it.Process) }},
+	{Header: "Public", Wide: true, Value: func(it model.ImageItem) string { return strconv.FormatBool(it.Public) }},
+}
 
 // flags for /v1/images/registry
 var (
@@ -30,13 +41,14 @@ var (
 	flagRepositoriesWith string
 	flagScannedAt        string
 	flagRisks            []string
+	flagAll              bool
 )
 
 var imagesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List images for a registry (scan results)",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := ctrl.LoadConfig()
+		cfg, err := loadConfig()
 		if err != nil {
 			fmt.Println("not configured:", err)
 			os.Exit(1)
@@ -74,9 +86,26 @@ var imagesListCmd = &cobra.Command{
 		for _, r := range flagRisks {
 			v.Add("risks[]", r)
 		}
-		rawQuery := v.Encode()
+		if imagesOutput.is("sarif") {
+			log, body, err := ctrl.ImagesSARIF(cmd.Context(), cfg, InvalidCert, v, flagLimit, flagAll, flagRisks, Version)
+			if err != nil {
+				fmt.Println("failed to export SARIF:", err)
+				if body != "" {
+					fmt.Println("response body:", body)
+				}
+				os.Exit(1)
+			}
+			printJSON(log)
+			return
+		}
 
-		items, body, endpoint, err := ctrl.ListImages(cfg, InvalidCert, rawQuery)
+		var items []model.ImageItem
+		var body, endpoint string
+		if flagAll {
+			items, body, endpoint, err = ctrl.ListAllImages(cmd.Context(), cfg, InvalidCert, v, flagLimit)
+		} else {
+			items, body, endpoint, err = ctrl.ListImages(cmd.Context(), cfg, InvalidCert, v.Encode())
+		}
 		if err != nil {
 			fmt.Println("failed to list images:", err)
 			if body != "" {
@@ -85,15 +114,7 @@ var imagesListCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if imagesOutput == "json" {
-			var pretty bytes.Buffer
-			if err := json.Indent(&pretty, []byte(body), "", "  "); err != nil {
-				fmt.Println(body)
-			} else {
-				fmt.Println(pretty.String())
-			}
-			return
-		} else if imagesOutput == "ollama" {
+		if imagesOutput.isAI() {
 			var risks []string
 			for _, item := range items {
 				risks = append(risks, item.RiskRating)
@@ -106,22 +127,11 @@ var imagesListCmd = &cobra.Command{
 				ReportTitle: "Kaspersky Container Security Image Assessment Report.",
 				ApiEndpoint: endpoint,
 			}
-			response, err := ctrl.SendToOllama(body, header)
-			if err != nil {
-				fmt.Println("failed to send to ollama:", err)
-				os.Exit(1)
-			}
-			fmt.Println(response)
+			sendToAI(cmd, cfg, body, header)
 			return
 		}
 
-		// default: tabbed table with columns: ID, Name, Registry, Risk
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tName\tRegistry\tRisk")
-		for _, it := range items {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", it.ID, it.Name, it.ImageRegistryName, it.RiskRating)
-		}
-		_ = w.Flush()
+		printItems(&imagesOutput, imageColumns, items, body)
 	},
 }
 
@@ -138,6 +148,7 @@ func init() {
 	imagesListCmd.Flags().StringVar(&flagRepositoriesWith, "repositoriesWith", "", "filter by repository risk rating (compliant|non-compliant|error|process)")
 	imagesListCmd.Flags().StringVar(&flagScannedAt, "scannedAt", "", "filter by scan timeframe (hour|day|week)")
 	imagesListCmd.Flags().StringSliceVar(&flagRisks, "risks", nil, "filter by risk types (malware|vulnerabilities|sensitive-data|misconfiguration) (repeatable)")
+	imagesListCmd.Flags().BoolVar(&flagAll, "all", false, "fetch every page (uses --limit as page size, ignores --page)")
 
-	imagesListCmd.Flags().StringVarP(&imagesOutput, "output", "o", "", "output format (\"json\" for raw JSON output, \"ollama\" to send to Ollama). Default: tabbed table")
+	addOutputFlags(imagesListCmd, &imagesOutput, "sarif", "ai")
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+var flagSBOMFormat string
+
+var imagesSbomCmd = &cobra.Command{
+	Use:   "sbom <id|name:tag>",
+	Short: "Export the SBOM of a scanned image",
+	Long: `Export the package inventory of a scanned image as a software bill of materials.
+
+Components carry their package URL (purl) and license; the vulnerabilities KCS found
+are linked to the affected components with their advisory URL, so the SBOM can be
+fed to Dependency-Track, GUAC or a similar tool.
+
+The image is looked up like in images get: a registry host, name or ID as the first
+segment of the name narrows the lookup to that registry.
+
+Formats:
+  cyclonedx-json   CycloneDX 1.5 (default)
+  spdx-json        SPDX 2.3
+
+Examples:
+  kcskit images sbom registry.local/app:1.4.2 > app.cdx.json
+  kcskit images sbom <image-id> --format spdx-json > app.spdx.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !slices.Contains(ctrl.SBOMFormats, flagSBOMFormat) {
+			fmt.Fprintf(os.Stderr, "error: invalid --format %q (want %s)\n", flagSBOMFormat, strings.Join(ctrl.SBOMFormats, "|"))
+			os.Exit(1)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		id, body, err := ctrl.ResolveImageID(cmd.Context(), cfg, InvalidCert, args[0])
+		if err != nil {
+			fmt.Println("failed to find image:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		detail, body, _, err := ctrl.GetImage(cmd.Context(), cfg, InvalidCert, id)
+		if err != nil {
+			fmt.Println("failed to get image:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		if len(detail.Packages) == 0 {
+			fmt.Fprintln(os.Stderr, "warning: KCS returned no package inventory; the SBOM only lists vulnerable packages")
+		}
+		switch flagSBOMFormat {
+		case "spdx-json":
+			printJSON(ctrl.ImageSPDX(*detail, Version, time.Now()))
+		default:
+			printJSON(ctrl.ImageCycloneDX(*detail, Version, time.Now()))
+		}
+	},
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesSbomCmd)
+
+	imagesSbomCmd.Flags().StringVar(&flagSBOMFormat, "format", "cyclonedx-json", "SBOM format ("+strings.Join(ctrl.SBOMFormats, "|")+").")
+}
@@ -2,35 +2,127 @@
 package cmd
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"strconv"
 	"strings"
-	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	ctrl "github.com/arturscheiner/kcskit/internal/controller"
 	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
 )
 
-var imagesScanOutput string
+var imagesScanOutput outputFlags
+
+// scanJobColumns are the table columns for manual scan jobs.
+var scanJobColumns = []output.Column[model.ManualJob]{
+	{Header: "ID", Value: func(j model.ManualJob) string { return j.ID }},
+	{Header: "Artifact", Value: func(j model.ManualJob) string { return j.ArtifactName }},
+	{Header: "Scanner", Value: func(j model.ManualJob) string { return j.ScannerName }},
+	{Header: "Status", Value: func(j model.ManualJob) string { return j.Status }},
+	{Header: "ArtifactID", Wide: true, Value: func(j model.ManualJob) string { return j.ArtifactID }},
+	{Header: "Created", Wide: true, Value: func(j model.ManualJob) string { return j.CreatedAt }},
+	{Header: "Updated", Wide: true, Value: func(j model.ManualJob) string { return j.UpdatedAt }},
+}
+
+// scanResultColumns are the table columns for a finished scan (images scan --wait).
+var scanResultColumns = []output.Column[model.ScanResult]{
+	{Header: "ID", Value: func(r model.ScanResult) string { return r.ID }},
+	{Header: "Artifact", Value: func(r model.ScanResult) string { return r.ArtifactName }},
+	{Header: "Status", Value: func(r model.ScanResult) string { return r.Status }},
+	{Header: "Risk", Value: func(r model.ScanResult) string {
+		return scanResultImage(r, func(it *model.ImageItem) string { return it.RiskRating })
+	}},
+	{Header: "NonCompliant", Value: func(r model.ScanResult) string {
+		return scanResultImage(r, func(it *model.ImageItem) string { return strconv.Itoa(it.NonCompliant) })
+	}},
+	{Header: "ImageID", Wide: true, Value: func(r model.ScanResult) string {
+		return scanResultImage(r, func(it *model.ImageItem) string { return it.ID })
+	}},
+	{Header: "Registry", Wide: true, Value: func(r model.ScanResult) string {
+		return scanResultImage(r, func(it *model.ImageItem) string { return it.ImageRegistryName })
+	}},
+	{Header: "Scanner", Wide: true, Value: func(r model.ScanResult) string { return r.ScannerName }},
+	{Header: "Updated", Wide: true, Value: func(r model.ScanResult) string { return r.UpdatedAt }},
+}
+
+// scanResultImage returns field of the scanned image, or "<none>" when there are no results.
+func scanResultImage(r model.ScanResult, field func(*model.ImageItem) string) string {
+	if r.Image == nil {
+		return "<none>"
+	}
+	return field(r.Image)
+}
+
 var flagArtifact string
 var flagRegistryID string
+var flagScanWait bool
+var flagScanPollInterval time.Duration
+var flagScanFromFile string
+var flagScanFromManifests string
+var flagScanConcurrency int
+var flagScanRate float64
+
+// bulkScanColumns are the table columns for images scan --from-file.
+var bulkScanColumns = []output.Column[model.BulkScanResult]{
+	{Header: "Ref", Value: func(r model.BulkScanResult) string { return r.Ref }},
+	{Header: "Registry", Value: func(r model.BulkScanResult) string { return r.Registry }},
+	{Header: "Job", Value: func(r model.BulkScanResult) string { return r.JobID }},
+	{Header: "Status", Value: func(r model.BulkScanResult) string { return r.Status }},
+	{Header: "Risk", Value: func(r model.BulkScanResult) string { return r.RiskRating }},
+	{Header: "Error", Value: func(r model.BulkScanResult) string { return r.Error }},
+	{Header: "Artifact", Wide: true, Value: func(r model.BulkScanResult) string { return r.Artifact }},
+}
 
 var imagesScanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Create a new scanning job for an artifact in a registry",
-	Long:  "Create a manual scan job. Both --artifact and --registry are required.",
+	Long: `Create a manual scan job. Both --artifact and --registry are required, unless
+--from-file or --from-manifests is used.
+
+With --wait the job is polled every --poll-interval until it finishes (progress is
+written to stderr) and the risk results of the artifact are printed. The global
+--timeout limits how long to wait.
+
+With --from-file (a file name, or - for stdin) one job is submitted per line of
+<registry>/<artifact>:<tag>. The registry part is matched against the host of each
+registry URL, then registry names and IDs; with --registry every line is an artifact
+in that registry. Jobs are submitted by --concurrency workers at no more than --rate
+jobs per second. Failed submissions are reported and do not stop the batch; the
+command exits with 1 when any artifact failed.
+
+--from-manifests <dir|file|-> collects every unique image referenced by Kubernetes
+workloads (Pods, Deployments, StatefulSets, DaemonSets, ReplicaSets, Jobs and
+CronJobs, including initContainers) and docker-compose services, and scans them the
+same way as --from-file. Use - to read rendered Helm output from stdin.
+
+Examples:
+  kcskit images scan --artifact nginx:latest --registry <registry-id>
+  kcskit images scan --artifact nginx:latest --registry <registry-id> --wait --timeout 10m
+  kcskit images scan --from-file refs.txt --concurrency 8 --rate 2
+  kcskit images scan --from-manifests k8s/
+  helm template my-release ./chart | kcskit images scan --from-manifests -`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if flagArtifact == "" || flagRegistryID == "" {
-			fmt.Fprintln(os.Stderr, "error: --artifact and --registry are required")
+		bulk := flagScanFromFile != "" || flagScanFromManifests != ""
+		if !bulk && (flagArtifact == "" || flagRegistryID == "") {
+			fmt.Fprintln(os.Stderr, "error: --artifact and --registry (or --from-file/--from-manifests) are required")
 			_ = cmd.Help()
 			os.Exit(1)
 		}
+		if flagScanRate < 0 || math.IsNaN(flagScanRate) {
+			fmt.Fprintf(os.Stderr, "error: invalid --rate %v (want jobs per second, 0 for no limit)\n", flagScanRate)
+			os.Exit(1)
+		}
 
-		cfg, err := ctrl.LoadConfig()
+		cfg, err := loadConfig()
 		if err != nil {
 			fmt.Println("not configured:", err)
 			os.Exit(1)
@@ -40,7 +132,17 @@ var imagesScanCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		job, body, endpoint, err := ctrl.CreateScan(cfg, InvalidCert, flagArtifact, flagRegistryID)
+		if bulk {
+			refs, source := readBulkRefs()
+			if len(refs) == 0 {
+				fmt.Println("no artifacts to scan in", source)
+				os.Exit(1)
+			}
+			runBulkScan(cmd, cfg, refs)
+			return
+		}
+
+		job, body, endpoint, err := ctrl.CreateScan(cmd.Context(), cfg, InvalidCert, flagArtifact, flagRegistryID)
 		if err != nil {
 			fmt.Println("failed to create scan:", err)
 			if body != "" {
@@ -49,15 +151,57 @@ var imagesScanCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if imagesScanOutput == "json" {
-			var pretty bytes.Buffer
-			if err := json.Indent(&pretty, []byte(body), "", "  "); err != nil {
-				fmt.Println(body)
-			} else {
-				fmt.Println(pretty.String())
+		if flagScanWait {
+			if job.ArtifactName == "" {
+				job.ArtifactName = flagArtifact
+			}
+			start := time.Now()
+			lastStatus := ""
+			result, err := ctrl.WaitForScan(cmd.Context(), cfg, InvalidCert, job, flagRegistryID, flagScanPollInterval, func(j model.ManualJob) {
+				if j.Status != lastStatus {
+					fmt.Fprintf(os.Stderr, "scan job %s (%s): %s [%s elapsed]\n", j.ID, j.ArtifactName, j.Status, time.Since(start).Round(time.Second))
+					lastStatus = j.Status
+				}
+			})
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					fmt.Fprintf(os.Stderr, "timed out waiting for scan job %s\n", job.ID)
+				} else {
+					fmt.Println("failed to wait for scan:", err)
+				}
+				os.Exit(1)
+			}
+			b, err := json.Marshal(result)
+			if err != nil {
+				fmt.Println("failed to encode scan result:", err)
+				os.Exit(1)
+			}
+			body = string(b)
+
+			if imagesScanOutput.isAI() {
+				risk := result.Status
+				if result.Image != nil {
+					risk = result.Image.RiskRating
+				}
+				header := model.OllamaHeader{
+					Command:     strings.Join(os.Args, " "),
+					Cluster:     "",
+					Risk:        risk,
+					ReportTitle: "Kaspersky Container Security Image Scan Assessment Report.",
+					ApiEndpoint: endpoint,
+				}
+				sendToAI(cmd, cfg, body, header)
+				return
+			}
+
+			printItems(&imagesScanOutput, scanResultColumns, []model.ScanResult{result}, body)
+			if ctrl.IsFailedStatus(result.Status) {
+				os.Exit(1)
 			}
 			return
-		} else if imagesScanOutput == "ollama" {
+		}
+
+		if imagesScanOutput.isAI() {
 			header := model.OllamaHeader{
 				Command:     strings.Join(os.Args, " "),
 				Cluster:     "",
@@ -65,30 +209,138 @@ var imagesScanCmd = &cobra.Command{
 				ReportTitle: "Kaspersky Container Security Image Scan Assessment Report.",
 				ApiEndpoint: endpoint,
 			}
-			response, err := ctrl.SendToOllama(body, header)
-			if err != nil {
-				fmt.Println("failed to send to ollama:", err)
-				os.Exit(1)
-			}
-			fmt.Println(response)
+			sendToAI(cmd, cfg, body, header)
 			return
 		}
 
-		// default tabbed table: ID | Artifact | Scanner | Status
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tArtifact\tScanner\tStatus")
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", job.ID, job.ArtifactName, job.ScannerName, job.Status)
-		_ = w.Flush()
+		printItems(&imagesScanOutput, scanJobColumns, []model.ManualJob{job}, body)
 	},
 }
 
 func init() {
 	imagesCmd.AddCommand(imagesScanCmd)
 
-	imagesScanCmd.Flags().StringVar(&flagArtifact, "artifact", "", "artifact reference, e.g. nginx:latest (required)")
-	imagesScanCmd.Flags().StringVar(&flagRegistryID, "registry", "", "registry ID where the artifact resides (required)")
-	imagesScanCmd.Flags().StringVarP(&imagesScanOutput, "output", "o", "", "output format (\"json\" for raw JSON output, \"ollama\" to send to Ollama). Default: tabbed table")
+	imagesScanCmd.Flags().StringVar(&flagArtifact, "artifact", "", "artifact reference, e.g. nginx:latest (required unless --from-file)")
+	imagesScanCmd.Flags().StringVar(&flagRegistryID, "registry", "", "registry ID where the artifact resides (required unless --from-file)")
+	imagesScanCmd.Flags().BoolVar(&flagScanWait, "wait", false, "Wait until the scan job finishes and print the risk results of the artifact.")
+	imagesScanCmd.Flags().StringVar(&flagScanFromFile, "from-file", "", "Scan every <registry>/<artifact>:<tag> listed in this file, one per line (- for stdin).")
+	imagesScanCmd.Flags().StringVar(&flagScanFromManifests, "from-manifests", "", "Scan every image referenced by the Kubernetes and docker-compose YAML in this file or directory (- for stdin).")
+	imagesScanCmd.Flags().IntVar(&flagScanConcurrency, "concurrency", 4, "Number of jobs submitted in parallel with --from-file or --from-manifests.")
+	imagesScanCmd.Flags().Float64Var(&flagScanRate, "rate", 5, "Maximum jobs submitted per second with --from-file or --from-manifests (0 for no limit).")
+	imagesScanCmd.Flags().DurationVar(&flagScanPollInterval, "poll-interval", 5*time.Second, "How often to poll the scan job with --wait.")
+	addOutputFlags(imagesScanCmd, &imagesScanOutput, "ai")
+
+	imagesScanCmd.MarkFlagsMutuallyExclusive("from-file", "from-manifests")
+}
+
+// readBulkRefs returns the artifact references of --from-file or --from-manifests
+// and a description of where they came from.
+func readBulkRefs() ([]string, string) {
+	if flagScanFromManifests != "" {
+		refs, warnings, err := ctrl.ManifestImages(flagScanFromManifests)
+		if err != nil {
+			fmt.Println("failed to read manifests:", err)
+			os.Exit(1)
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, "warning:", w)
+		}
+		fmt.Fprintf(os.Stderr, "found %d images in %s\n", len(refs), flagScanFromManifests)
+		return refs, flagScanFromManifests
+	}
+
+	in := io.Reader(os.Stdin)
+	if flagScanFromFile != "-" {
+		f, err := os.Open(flagScanFromFile)
+		if err != nil {
+			fmt.Println("failed to read artifact list:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	refs, err := ctrl.ReadScanRefs(in)
+	if err != nil {
+		fmt.Println("failed to read artifact list:", err)
+		os.Exit(1)
+	}
+	return refs, flagScanFromFile
+}
+
+// runBulkScan implements images scan --from-file and --from-manifests.
+func runBulkScan(cmd *cobra.Command, cfg model.Config, refs []string) {
+	var resolver *ctrl.RegistryResolver
+	if flagRegistryID == "" {
+		registries, body, _, err := ctrl.ListRegistries(cmd.Context(), cfg, InvalidCert)
+		if err != nil {
+			fmt.Println("failed to list registries:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		resolver = ctrl.NewRegistryResolver(registries)
+	}
+
+	// unresolved references are reported with the results but not submitted
+	var targets []model.ScanTarget
+	var unresolved []model.BulkScanResult
+	for _, ref := range refs {
+		if resolver == nil {
+			targets = append(targets, model.ScanTarget{Ref: ref, RegistryID: flagRegistryID, RegistryName: flagRegistryID, Artifact: ctrl.NormalizeArtifact(ref)})
+			continue
+		}
+		t, err := resolver.Resolve(ref)
+		if err != nil {
+			unresolved = append(unresolved, model.BulkScanResult{Ref: ref, Status: "unresolved", Error: err.Error()})
+			continue
+		}
+		targets = append(targets, t)
+	}
+
+	done := 0
+	results := ctrl.BulkScan(cmd.Context(), cfg, InvalidCert, targets, ctrl.BulkScanOptions{
+		Concurrency:  flagScanConcurrency,
+		Rate:         flagScanRate,
+		Wait:         flagScanWait,
+		PollInterval: flagScanPollInterval,
+	}, func(r model.BulkScanResult) {
+		done++
+		if r.Error != "" {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s: %s\n", done, len(targets), r.Ref, r.Error)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s: job %s %s\n", done, len(targets), r.Ref, r.JobID, r.Status)
+	})
+	results = append(results, unresolved...)
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		fmt.Println("failed to encode scan results:", err)
+		os.Exit(1)
+	}
+	failed, summary := ctrl.SummarizeBulkScan(results)
 
-	_ = imagesScanCmd.MarkFlagRequired("artifact")
-	_ = imagesScanCmd.MarkFlagRequired("registry")
+	if imagesScanOutput.isAI() {
+		header := model.OllamaHeader{
+			Command:     strings.Join(os.Args, " "),
+			Cluster:     "",
+			Risk:        summary,
+			ReportTitle: "Kaspersky Container Security Bulk Image Scan Report.",
+			ApiEndpoint: "/v1/scans",
+		}
+		sendToAI(cmd, cfg, string(b), header)
+	} else {
+		printItems(&imagesScanOutput, bulkScanColumns, results, string(b))
+		// keep machine-readable output clean
+		if imagesScanOutput.isTable() {
+			fmt.Println()
+			fmt.Println(summary)
+		} else {
+			fmt.Fprintln(os.Stderr, summary)
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+// outputFlags holds the output flags shared by every command that prints API results.
+type outputFlags struct {
+	format    string
+	noHeaders bool
+	sortBy    string
+	// interactive keeps chatting about the results after an AI report.
+	interactive bool
+	// prompt overrides the prompt template of an AI report.
+	prompt string
+	// extra lists command-specific formats (e.g. "sarif") handled by the command itself.
+	extra []string
+}
+
+// formatAI is the extra format of the commands that send their results to the
+// AI model (-o ai, alias "ollama"); commands without it reject -o ai.
+const formatAI = "ai"
+
+// addOutputFlags registers -o/--output, --no-headers and --sort-by on c and
+// validates them before c runs. extra names additional formats the command
+// handles itself (check them with f.is); formatAI also registers --interactive
+// and --prompt, and lists c in 'kcskit ai prompts list'.
+func addOutputFlags(c *cobra.Command, f *outputFlags, extra ...string) {
+	f.extra = extra
+	formats := output.Formats
+	ai := false
+	for _, e := range extra {
+		if e == formatAI {
+			ai = true
+			continue
+		}
+		formats += "|" + e
+	}
+	usage := "output format: " + formats + ". Default: table"
+	if ai {
+		usage = "output format: " + formats + ", or \"ai\" (alias \"ollama\") to send to the AI model. Default: table"
+	}
+	c.Flags().StringVarP(&f.format, "output", "o", "", usage)
+	c.Flags().BoolVar(&f.noHeaders, "no-headers", false, "do not print the header row (table, wide, csv, tsv, custom-columns)")
+	c.Flags().StringVar(&f.sortBy, "sort-by", "", "sort items client-side by a JSONPath expression, e.g. .riskRating")
+	if ai {
+		c.Flags().BoolVar(&f.interactive, "interactive", false, "with -o ai, keep chatting with the model about the results after the report")
+		c.Flags().StringVar(&f.prompt, "prompt", "", "with -o ai, the prompt template to use: a file, or a name in ~/.kcskit/prompts (see 'kcskit ai prompts')")
+		markAIReport(c)
+	}
+
+	c.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if f.isAI() {
+			if !ai {
+				return fmt.Errorf("-o %s is not supported by %s", f.format, cmd.CommandPath())
+			}
+			return nil
+		}
+		if f.interactive {
+			return fmt.Errorf("--interactive requires -o ai")
+		}
+		if f.prompt != "" {
+			return fmt.Errorf("--prompt requires -o ai")
+		}
+		for _, e := range f.extra {
+			if e != formatAI && f.is(e) {
+				return nil
+			}
+		}
+		return f.options().Validate()
+	}
+}
+
+// is reports whether the selected format is the command-specific format name.
+func (f *outputFlags) is(name string) bool {
+	return f.format == name
+}
+
+// isTable reports whether the selected format is the human-readable table (or wide) output.
+func (f *outputFlags) isTable() bool {
+	return f.format == "" || f.format == "table" || f.format == "wide"
+}
+
+// isAI reports whether results should be sent to the AI model instead of printed.
+func (f *outputFlags) isAI() bool {
+	return f.format == "ollama" || f.format == "ai"
+}
+
+func (f *outputFlags) options() output.Options {
+	return output.Options{Format: f.format, NoHeaders: f.noHeaders, SortBy: f.sortBy}
+}
+
+// printJSON prints v as indented JSON and exits on error.
+func printJSON(v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Println("failed to print output:", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(b))
+}
+
+// printItems prints items (parsed from the raw API body) in the selected format and exits on error.
+func printItems[T any](f *outputFlags, columns []output.Column[T], items []T, body string) {
+	if err := output.Print(os.Stdout, f.options(), columns, items, []byte(body)); err != nil {
+		fmt.Println("failed to print output:", err)
+		os.Exit(1)
+	}
+}
@@ -1,13 +1,46 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
 )
 
 var registriesCmd = &cobra.Command{
 	Use:   "registries",
 	Short: "Manage image registries integrations",
-	Long:  "Manage image registries integrations configured in Kaspersky Container Security (list, show, ...).",
+	Long:  "Manage image registries integrations configured in Kaspersky Container Security (list, get, create, update, delete, test).",
+}
+
+// mustLoadRegistryConfig loads and validates the configuration or exits.
+func mustLoadRegistryConfig() model.Config {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Println("not configured:", err)
+		os.Exit(1)
+	}
+	if err := ctrl.ValidateConfig(cfg); err != nil {
+		fmt.Println("not configured:", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// mustFindRegistry resolves a registry ID or name or exits.
+func mustFindRegistry(cmd *cobra.Command, cfg model.Config, ref string) *model.RegistryItem {
+	reg, body, err := ctrl.FindRegistry(cmd.Context(), cfg, InvalidCert, ref)
+	if err != nil {
+		fmt.Println("failed to find registry:", err)
+		if body != "" {
+			fmt.Println("response body:", body)
+		}
+		os.Exit(1)
+	}
+	return reg
 }
 
 func init() {
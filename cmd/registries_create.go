@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// registrySpecFlags are the flags describing a registry for create and update.
+type registrySpecFlags struct {
+	filename          string
+	name              string
+	registryType      string
+	url               string
+	apiUrl            string
+	description       string
+	authType          string
+	username          string
+	password          string
+	token             string
+	accessKeyID       string
+	secretAccessKey   string
+	region            string
+	serviceAccountKey string
+}
+
+func (f *registrySpecFlags) bind(c *cobra.Command) {
+	c.Flags().StringVarP(&f.filename, "filename", "f", "", "YAML spec file with kind: Registry documents (- for stdin).")
+	c.Flags().StringVar(&f.name, "name", "", "Registry name.")
+	c.Flags().StringVar(&f.registryType, "type", "", "Registry type (e.g. harbor, dockerhub, gitlab, nexus, jfrog, ecr, acr, gcr).")
+	c.Flags().StringVar(&f.url, "url", "", "Registry URL.")
+	c.Flags().StringVar(&f.apiUrl, "api-url", "", "Registry API URL, when it differs from --url.")
+	c.Flags().StringVar(&f.description, "description", "", "Registry description.")
+	c.Flags().StringVar(&f.authType, "auth-type", "", "Authentication type ("+strings.Join(ctrl.AuthTypeNames(), "|")+").")
+	c.Flags().StringVar(&f.username, "username", "", "User name (basic).")
+	c.Flags().StringVar(&f.password, "password", "", "Password (basic).")
+	c.Flags().StringVar(&f.token, "token", "", "Access token (token).")
+	c.Flags().StringVar(&f.accessKeyID, "access-key-id", "", "Access key ID (aws).")
+	c.Flags().StringVar(&f.secretAccessKey, "secret-access-key", "", "Secret access key (aws).")
+	c.Flags().StringVar(&f.region, "region", "", "Region (aws).")
+	c.Flags().StringVar(&f.serviceAccountKey, "service-account-key-file", "", "File with the JSON service account key (service-account).")
+}
+
+// apply copies the flags set on the command line into spec.
+func (f *registrySpecFlags) apply(cmd *cobra.Command, spec *model.RegistrySpec) error {
+	set := func(name string, dst *string, v string) {
+		if cmd.Flags().Changed(name) {
+			*dst = v
+		}
+	}
+	set("name", &spec.RegistryName, f.name)
+	set("type", &spec.RegistryType, f.registryType)
+	set("url", &spec.RegistryUrl, f.url)
+	set("api-url", &spec.ApiUrl, f.apiUrl)
+	set("description", &spec.Description, f.description)
+	set("auth-type", &spec.AuthenticationType, f.authType)
+	set("username", &spec.Username, f.username)
+	set("password", &spec.Password, f.password)
+	set("token", &spec.Token, f.token)
+	set("access-key-id", &spec.AccessKeyID, f.accessKeyID)
+	set("secret-access-key", &spec.SecretAccessKey, f.secretAccessKey)
+	set("region", &spec.Region, f.region)
+	if f.serviceAccountKey != "" {
+		b, err := os.ReadFile(f.serviceAccountKey)
+		if err != nil {
+			return err
+		}
+		spec.ServiceAccountKey = string(b)
+	}
+	return nil
+}
+
+// open opens the spec file given with -f, or stdin for "-".
+func (f *registrySpecFlags) open() (io.ReadCloser, error) {
+	if f.filename == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(f.filename)
+}
+
+// specs reads the spec file given with -f, or returns a single empty spec.
+func (f *registrySpecFlags) specs() ([]model.RegistrySpec, error) {
+	if f.filename == "" {
+		return []model.RegistrySpec{{Kind: "Registry", RegistryAuth: model.RegistryAuth{AuthenticationType: "none"}}}, nil
+	}
+	in, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	specs, err := ctrl.ReadRegistrySpecs(in)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.filename, err)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("%s: no registries found", f.filename)
+	}
+	return specs, nil
+}
+
+var (
+	registriesCreateOutput outputFlags
+	registriesCreateFlags  registrySpecFlags
+)
+
+var registriesCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create image registry integrations",
+	Long: `Create a registry integration from flags, or one per kind: Registry document
+of a YAML spec file (-f). Flags given together with -f override the file.
+
+Authentication types and their credentials:
+  none             -
+  basic            --username, --password
+  token            --token
+  aws              --access-key-id, --secret-access-key, --region
+  service-account  --service-account-key-file
+
+In spec files credentials may reference environment variables as ${VAR}.
+
+Examples:
+  kcskit registries create --name harbor --type harbor --url https://harbor.local \
+    --auth-type basic --username 'robot$kcs' --password "$HARBOR_PASSWORD"
+  kcskit registries create -f registries.yaml
+
+Spec file:
+  kind: Registry
+  name: harbor
+  type: harbor
+  url: https://harbor.local
+  description: Production Harbor
+  auth:
+    type: basic
+    username: robot$kcs
+    password: ${HARBOR_PASSWORD}`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		specs, err := registriesCreateFlags.specs()
+		if err != nil {
+			fmt.Println("failed to read registry spec:", err)
+			os.Exit(1)
+		}
+		for i := range specs {
+			if err := registriesCreateFlags.apply(cmd, &specs[i]); err != nil {
+				fmt.Println("failed to read registry flags:", err)
+				os.Exit(1)
+			}
+			if err := ctrl.ValidateRegistrySpec(specs[i], true); err != nil {
+				fmt.Println("invalid registry:", err)
+				os.Exit(1)
+			}
+		}
+
+		cfg := mustLoadRegistryConfig()
+		var created []model.RegistryItem
+		for _, spec := range specs {
+			item, body, _, err := ctrl.CreateRegistry(cmd.Context(), cfg, InvalidCert, spec)
+			if err != nil {
+				fmt.Printf("failed to create registry %q: %v\n", spec.RegistryName, err)
+				if body != "" {
+					fmt.Println("response body:", body)
+				}
+				os.Exit(1)
+			}
+			created = append(created, *item)
+		}
+
+		b, err := json.Marshal(created)
+		if err != nil {
+			fmt.Println("failed to encode registries:", err)
+			os.Exit(1)
+		}
+		printItems(&registriesCreateOutput, registryColumns, created, string(b))
+	},
+}
+
+func init() {
+	registriesCmd.AddCommand(registriesCreateCmd)
+	registriesCreateFlags.bind(registriesCreateCmd)
+	addOutputFlags(registriesCreateCmd, &registriesCreateOutput)
+}
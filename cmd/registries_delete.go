@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+var registriesDeleteCmd = &cobra.Command{
+	Use:   "delete <id|name>",
+	Short: "Delete an image registry integration",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadRegistryConfig()
+		reg := mustFindRegistry(cmd, cfg, args[0])
+
+		body, _, err := ctrl.DeleteRegistry(cmd.Context(), cfg, InvalidCert, reg.ID)
+		if err != nil {
+			fmt.Println("failed to delete registry:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("registry %q (%s) deleted\n", reg.RegistryName, reg.ID)
+	},
+}
+
+func init() {
+	registriesCmd.AddCommand(registriesDeleteCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+var registriesGetOutput outputFlags
+
+var registriesGetCmd = &cobra.Command{
+	Use:   "get <id|name>",
+	Short: "Show an image registry integration",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadRegistryConfig()
+		reg := mustFindRegistry(cmd, cfg, args[0])
+
+		item, body, endpoint, err := ctrl.GetRegistry(cmd.Context(), cfg, InvalidCert, reg.ID)
+		if err != nil {
+			fmt.Println("failed to get registry:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		if registriesGetOutput.isAI() {
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     "",
+				Risk:        item.Status,
+				ReportTitle: "Kaspersky Container Security Registry Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		printItems(&registriesGetOutput, registryColumns, []model.RegistryItem{*item}, body)
+	},
+}
+
+func init() {
+	registriesCmd.AddCommand(registriesGetCmd)
+	addOutputFlags(registriesGetCmd, &registriesGetOutput, "ai")
+}
@@ -1,27 +1,42 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	ctrl "github.com/arturscheiner/kcskit/internal/controller"
 	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
 )
 
-var registriesOutput string
+var registriesOutput outputFlags
+
+// registryColumns are the table columns for image registries.
+var registryColumns = []output.Column[model.RegistryItem]{
+	{Header: "ID", Value: func(it model.RegistryItem) string { return it.ID }},
+	{Header: "Name", Value: func(it model.RegistryItem) string { return it.RegistryName }},
+	{Header: "Type", Value: func(it model.RegistryItem) string { return it.RegistryType }},
+	{Header: "Url", Value: func(it model.RegistryItem) string {
+		if it.ApiUrl == "" {
+			return it.RegistryUrl
+		}
+		return it.ApiUrl
+	}},
+	{Header: "Auth", Wide: true, Value: func(it model.RegistryItem) string { return it.AuthenticationType }},
+	{Header: "Status", Wide: true, Value: func(it model.RegistryItem) string { return it.Status }},
+	{Header: "LastChecked", Wide: true, Value: func(it model.RegistryItem) string { return it.LastChecked }},
+	{Header: "Description", Wide: true, Value: func(it model.RegistryItem) string { return it.Description }},
+}
 var registriesInvalidCert bool
 
 var registriesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List configured image registries",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := ctrl.LoadConfig()
+		cfg, err := loadConfig()
 		if err != nil {
 			fmt.Println("not configured:", err)
 			os.Exit(1)
@@ -31,7 +46,7 @@ var registriesListCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		items, body, endpoint, err := ctrl.ListRegistries(cfg, registriesInvalidCert)
+		items, body, endpoint, err := ctrl.ListRegistries(cmd.Context(), cfg, registriesInvalidCert)
 		if err != nil {
 			fmt.Println("failed to list registries:", err)
 			if body != "" {
@@ -40,15 +55,7 @@ var registriesListCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if registriesOutput == "json" {
-			var pretty bytes.Buffer
-			if err := json.Indent(&pretty, []byte(body), "", "  "); err != nil {
-				fmt.Println(body)
-			} else {
-				fmt.Println(pretty.String())
-			}
-			return
-		} else if registriesOutput == "ollama" {
+		if registriesOutput.isAI() {
 			header := model.OllamaHeader{
 				Command:     strings.Join(os.Args, " "),
 				Cluster:     "",
@@ -56,31 +63,16 @@ var registriesListCmd = &cobra.Command{
 				ReportTitle: "Kaspersky Container Security Registries Assessment Report.",
 				ApiEndpoint: endpoint,
 			}
-			response, err := ctrl.SendToOllama(body, header)
-			if err != nil {
-				fmt.Println("failed to send to ollama:", err)
-				os.Exit(1)
-			}
-			fmt.Println(response)
+			sendToAI(cmd, cfg, body, header)
 			return
 		}
 
-		// default: tabbed table with columns: ID, Name, Type, Url
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tName\tType\tUrl")
-		for _, it := range items {
-			urlToShow := it.ApiUrl
-			if urlToShow == "" {
-				urlToShow = it.RegistryUrl
-			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", it.ID, it.RegistryName, it.RegistryType, urlToShow)
-		}
-		_ = w.Flush()
+		printItems(&registriesOutput, registryColumns, items, body)
 	},
 }
 
 func init() {
 	registriesCmd.AddCommand(registriesListCmd)
-	registriesListCmd.Flags().StringVarP(&registriesOutput, "output", "o", "", "output format (\"json\" for raw JSON output, \"ollama\" to send to Ollama). Default: tabbed table")
+	addOutputFlags(registriesListCmd, &registriesOutput, "ai")
 	registriesListCmd.Flags().BoolVarP(&registriesInvalidCert, "invalid-cert", "i", false, "ignore TLS certificate validation when performing API requests")
 }
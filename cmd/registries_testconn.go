@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+	"github.com/arturscheiner/kcskit/internal/output"
+)
+
+var registriesTestOutput outputFlags
+
+// registryTestColumns are the table columns for registries test.
+var registryTestColumns = []output.Column[model.RegistryItem]{
+	{Header: "ID", Value: func(it model.RegistryItem) string { return it.ID }},
+	{Header: "Name", Value: func(it model.RegistryItem) string { return it.RegistryName }},
+	{Header: "Status", Value: func(it model.RegistryItem) string { return it.Status }},
+	{Header: "Message", Value: func(it model.RegistryItem) string { return it.Message }},
+	{Header: "LastChecked", Value: func(it model.RegistryItem) string { return it.LastChecked }},
+}
+
+var registriesTestCmd = &cobra.Command{
+	Use:   "test <id|name>",
+	Short: "Test the connection to an image registry",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadRegistryConfig()
+		reg := mustFindRegistry(cmd, cfg, args[0])
+
+		item, body, _, err := ctrl.TestRegistry(cmd.Context(), cfg, InvalidCert, reg.ID)
+		if err != nil {
+			fmt.Println("failed to test registry:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		printItems(&registriesTestOutput, registryTestColumns, []model.RegistryItem{*item}, body)
+	},
+}
+
+func init() {
+	registriesCmd.AddCommand(registriesTestCmd)
+	addOutputFlags(registriesTestCmd, &registriesTestOutput)
+}
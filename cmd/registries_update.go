@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+var (
+	registriesUpdateOutput outputFlags
+	registriesUpdateFlags  registrySpecFlags
+)
+
+var registriesUpdateCmd = &cobra.Command{
+	Use:   "update <id|name>",
+	Short: "Update an image registry integration",
+	Long: `Update a registry integration. The current settings are kept and only the
+given flags, or the fields present in the spec file given with -f, are changed;
+flags override the file. Credentials are only sent when given; otherwise the
+stored ones are kept.
+
+Examples:
+  kcskit registries update harbor --description "Production Harbor"
+  kcskit registries update harbor --auth-type token --token "$HARBOR_TOKEN"
+  kcskit registries update harbor -f harbor.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadRegistryConfig()
+		reg := mustFindRegistry(cmd, cfg, args[0])
+
+		spec := ctrl.RegistrySpecFromItem(*reg)
+		if registriesUpdateFlags.filename != "" {
+			in, err := registriesUpdateFlags.open()
+			if err != nil {
+				fmt.Println("failed to read registry spec:", err)
+				os.Exit(1)
+			}
+			spec, err = ctrl.MergeRegistrySpec(in, spec)
+			in.Close()
+			if err != nil {
+				fmt.Printf("failed to read registry spec: %s: %v\n", registriesUpdateFlags.filename, err)
+				os.Exit(1)
+			}
+		}
+		if err := registriesUpdateFlags.apply(cmd, &spec); err != nil {
+			fmt.Println("failed to read registry flags:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateRegistrySpec(spec, false); err != nil {
+			fmt.Println("invalid registry:", err)
+			os.Exit(1)
+		}
+
+		item, body, _, err := ctrl.UpdateRegistry(cmd.Context(), cfg, InvalidCert, reg.ID, spec)
+		if err != nil {
+			fmt.Println("failed to update registry:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		printItems(&registriesUpdateOutput, registryColumns, []model.RegistryItem{*item}, body)
+	},
+}
+
+func init() {
+	registriesCmd.AddCommand(registriesUpdateCmd)
+	registriesUpdateFlags.bind(registriesUpdateCmd)
+	addOutputFlags(registriesUpdateCmd, &registriesUpdateOutput)
+}
@@ -4,9 +4,16 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
 )
 
 // Version is set at build time via -ldflags. Default is "dev".
@@ -19,6 +26,27 @@ var Date = ""
 // Global flag: ignore TLS certificate validation
 var InvalidCert bool
 
+// Global flags: HTTP timeout and retry policy overrides (take precedence over the config file)
+var (
+	globalHTTPTimeout        time.Duration
+	globalRetries            int
+	globalRetryBackoff       time.Duration
+	globalRetryMaxBackoff    time.Duration
+	globalRetryNonIdempotent bool
+)
+
+// Global flag: config file location (default $KCSKIT_CONFIG or $HOME/.kcskit/config)
+var globalConfigPath string
+
+// Global flag: configuration context (profile) to use instead of the current one
+var globalContext string
+
+// Global flag: overall deadline for the whole command (0 means no deadline)
+var globalTimeout time.Duration
+
+// cancelTimeout releases the --timeout deadline context once the command finished.
+var cancelTimeout context.CancelFunc = func() {}
+
 var rootCmd = &cobra.Command{
 	Use:   "kcskit",
 	Short: "kcskit — lightweight CLI for Kaspersky Container Security (KCS)",
@@ -32,6 +60,16 @@ For help on a specific command:
 	// VersionTemplate: "kcskit version: {{.Version}}{{if .Commit}}\ncommit: {{.Commit}}{{end}}{{if .Date}}\nbuilt:  {{.Date}}{{end}}\n",
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if globalConfigPath != "" {
+			ctrl.SetConfigPath(globalConfigPath)
+		}
+		if globalTimeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), globalTimeout)
+			cancelTimeout = cancel
+			cmd.SetContext(ctx)
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
 		os.Exit(0)
@@ -40,9 +78,15 @@ For help on a specific command:
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// SIGINT/SIGTERM cancel the command context, aborting in-flight API and Ollama requests.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
 	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
 		os.Exit(1)
 	}
 }
@@ -51,13 +95,28 @@ func init() {
 	// register global persistent flag for ignoring invalid TLS certificates
 	rootCmd.PersistentFlags().BoolVarP(&InvalidCert, "invalid-cert", "i", false, "ignore TLS certificate validation for all commands (use with caution)")
 
+	// config file location
+	rootCmd.PersistentFlags().StringVar(&globalConfigPath, "config", "", "config file path (default: $KCSKIT_CONFIG or $HOME/.kcskit/config)")
+
+	// configuration profile selection for a single invocation
+	rootCmd.PersistentFlags().StringVar(&globalContext, "context", "", "configuration context (profile) to use for this command (default: current context)")
+
+	// overall deadline for the command, including retries and AI requests
+	rootCmd.PersistentFlags().DurationVar(&globalTimeout, "timeout", 0, "overall deadline for the command, e.g. 2m (0 = no deadline)")
+
+	// HTTP timeout and retry policy for KCS API requests
+	rootCmd.PersistentFlags().DurationVar(&globalHTTPTimeout, "http-timeout", 0, "per-attempt HTTP timeout for KCS API requests (overrides config, default 8s)")
+	rootCmd.PersistentFlags().IntVar(&globalRetries, "retries", 0, "maximum attempts per KCS API request, including the first (overrides config, default 3)")
+	rootCmd.PersistentFlags().DurationVar(&globalRetryBackoff, "retry-backoff", 0, "initial retry backoff (overrides config, default 500ms)")
+	rootCmd.PersistentFlags().DurationVar(&globalRetryMaxBackoff, "retry-max-backoff", 0, "maximum retry backoff (overrides config, default 10s)")
+	rootCmd.PersistentFlags().BoolVar(&globalRetryNonIdempotent, "retry-non-idempotent", false, "also retry non-idempotent requests such as POST (overrides config)")
+
 	// set a version template (VersionTemplate field is unexported; use setter)
 	rootCmd.SetVersionTemplate("kcskit version: {{.Version}}\n")
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.kcskit.yaml)")
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
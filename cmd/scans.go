@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var scansCmd = &cobra.Command{
+	Use:   "scans",
+	Short: "Manage manual scan jobs",
+	Long:  "Commands to look up, list and cancel manual scan jobs created with 'kcskit images scan'.",
+}
+
+func init() {
+	rootCmd.AddCommand(scansCmd)
+}
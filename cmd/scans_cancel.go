@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+)
+
+var scansCancelCmd = &cobra.Command{
+	Use:   "cancel <job-id>",
+	Short: "Cancel a manual scan job",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		body, _, err := ctrl.CancelScan(cmd.Context(), cfg, InvalidCert, args[0])
+		if err != nil {
+			fmt.Println("failed to cancel scan:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("scan job %s cancelled\n", args[0])
+	},
+}
+
+func init() {
+	scansCmd.AddCommand(scansCancelCmd)
+}
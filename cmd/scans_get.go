@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+var scansGetOutput outputFlags
+
+var scansGetCmd = &cobra.Command{
+	Use:   "get <job-id>",
+	Short: "Show a manual scan job",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		job, body, endpoint, err := ctrl.GetScan(cmd.Context(), cfg, InvalidCert, args[0])
+		if err != nil {
+			fmt.Println("failed to get scan:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		if scansGetOutput.isAI() {
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     "",
+				Risk:        job.Status,
+				ReportTitle: "Kaspersky Container Security Scan Job Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		printItems(&scansGetOutput, scanJobColumns, []model.ManualJob{job}, body)
+	},
+}
+
+func init() {
+	scansCmd.AddCommand(scansGetCmd)
+	addOutputFlags(scansGetCmd, &scansGetOutput, "ai")
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ctrl "github.com/arturscheiner/kcskit/internal/controller"
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+var (
+	scansListOutput   outputFlags
+	flagScansPage     int
+	flagScansLimit    int
+	flagScansSort     string
+	flagScansBy       string
+	flagScansStatus   string
+	flagScansScanner  string
+	flagScansArtifact string
+	flagScansAll      bool
+)
+
+var scansListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List manual scan jobs",
+	Long: `List manual scan jobs, newest first.
+
+The --status, --scanner and --artifact filters are sent to the API and applied
+again to the returned jobs (--artifact matches any part of the artifact name).
+
+Examples:
+  kcskit scans list --status in_progress
+  kcskit scans list --artifact nginx --all -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+		if err := ctrl.ValidateConfig(cfg); err != nil {
+			fmt.Println("not configured:", err)
+			os.Exit(1)
+		}
+
+		v := url.Values{}
+		if flagScansSort != "" {
+			v.Set("sort", flagScansSort)
+		}
+		if flagScansBy != "" {
+			v.Set("by", flagScansBy)
+		}
+		if flagScansStatus != "" {
+			v.Set("status", flagScansStatus)
+		}
+		if flagScansScanner != "" {
+			v.Set("scannerName", flagScansScanner)
+		}
+		if flagScansArtifact != "" {
+			v.Set("artifactName", flagScansArtifact)
+		}
+
+		var jobs []model.ManualJob
+		var body, endpoint string
+		if flagScansAll {
+			jobs, body, endpoint, err = ctrl.ListAllScans(cmd.Context(), cfg, InvalidCert, v, flagScansLimit)
+		} else {
+			v.Set("page", strconv.Itoa(flagScansPage))
+			v.Set("limit", strconv.Itoa(flagScansLimit))
+			jobs, body, endpoint, err = ctrl.ListScans(cmd.Context(), cfg, InvalidCert, v.Encode())
+		}
+		if err != nil {
+			fmt.Println("failed to list scans:", err)
+			if body != "" {
+				fmt.Println("response body:", body)
+			}
+			os.Exit(1)
+		}
+
+		if flagScansStatus != "" || flagScansScanner != "" || flagScansArtifact != "" {
+			filtered := ctrl.FilterScans(jobs, flagScansStatus, flagScansScanner, flagScansArtifact)
+			if len(filtered) != len(jobs) {
+				// the raw body no longer matches the items; render the filtered jobs
+				jobs = filtered
+				b, err := json.Marshal(model.ManualJobsResponse{Total: len(jobs), Page: 1, Items: jobs})
+				if err != nil {
+					fmt.Println("failed to encode scans:", err)
+					os.Exit(1)
+				}
+				body = string(b)
+			}
+		}
+
+		if scansListOutput.isAI() {
+			var statuses []string
+			for _, j := range jobs {
+				statuses = append(statuses, j.Status)
+			}
+			header := model.OllamaHeader{
+				Command:     strings.Join(os.Args, " "),
+				Cluster:     "",
+				Risk:        strings.Join(statuses, ", "),
+				ReportTitle: "Kaspersky Container Security Scan Jobs Report.",
+				ApiEndpoint: endpoint,
+			}
+			sendToAI(cmd, cfg, body, header)
+			return
+		}
+
+		printItems(&scansListOutput, scanJobColumns, jobs, body)
+	},
+}
+
+func init() {
+	scansCmd.AddCommand(scansListCmd)
+
+	scansListCmd.Flags().IntVar(&flagScansPage, "page", 1, "The page number to retrieve for paginated results.")
+	scansListCmd.Flags().IntVar(&flagScansLimit, "limit", 50, "The number of items to include per page.")
+	scansListCmd.Flags().StringVar(&flagScansSort, "sort", "createdAt", "Sort by value (createdAt|updatedAt|artifactName|scannerName|status)")
+	scansListCmd.Flags().StringVar(&flagScansBy, "by", "desc", "Sort by order (asc|desc)")
+	scansListCmd.Flags().StringVar(&flagScansStatus, "status", "", "Filter by job status (e.g. in_progress, finished, failed).")
+	scansListCmd.Flags().StringVar(&flagScansScanner, "scanner", "", "Filter by scanner name.")
+	scansListCmd.Flags().StringVar(&flagScansArtifact, "artifact", "", "Filter by artifact name (substring match).")
+	scansListCmd.Flags().BoolVar(&flagScansAll, "all", false, "Fetch every page (uses --limit as page size, ignores --page).")
+
+	addOutputFlags(scansListCmd, &scansListOutput, "ai")
+}
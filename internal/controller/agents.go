@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// agentPageSize is the page size used to fetch every agent group or agent.
+const agentPageSize = 100
+
+// DefaultStaleAfter is the heartbeat age after which --stale reports an agent.
+const DefaultStaleAfter = 10 * time.Minute
+
+// ListAgentGroups returns every agent group (GET /v1/agent-groups), a merged
+// JSON body and the endpoint.
+func ListAgentGroups(ctx context.Context, cfg model.Config, invalidCert bool) ([]model.AgentGroup, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/agent-groups"
+	items, total, body, err := listAll(ctx, client, endpoint, url.Values{}, agentPageSize, "agent groups", func(r *model.AgentGroupsResponse) ([]model.AgentGroup, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+
+	merged, err := json.Marshal(model.AgentGroupsResponse{Total: total, Page: 1, Items: items})
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return items, string(merged), endpoint, nil
+}
+
+// FindAgentGroup resolves an agent group ID or name, or the ID or name of the
+// cluster the group monitors (through the cluster's AgentGroupId).
+func FindAgentGroup(ctx context.Context, cfg model.Config, invalidCert bool, ref string) (*model.AgentGroup, string, error) {
+	groups, body, _, err := ListAgentGroups(ctx, cfg, invalidCert)
+	if err != nil {
+		return nil, body, err
+	}
+	for i := range groups {
+		if groups[i].ID == ref {
+			return &groups[i], "", nil
+		}
+	}
+	var found []model.AgentGroup
+	for _, g := range groups {
+		if strings.EqualFold(g.Name, ref) {
+			found = append(found, g)
+		}
+	}
+	if len(found) > 1 {
+		return nil, "", fmt.Errorf("agent group name %q is ambiguous (%d groups), use the ID", ref, len(found))
+	}
+	if len(found) == 1 {
+		return &found[0], "", nil
+	}
+
+	cluster, body, err := FindCluster(ctx, cfg, invalidCert, ref)
+	if err != nil {
+		return nil, body, fmt.Errorf("agent group %q not found (%w)", ref, err)
+	}
+	for i := range groups {
+		if groups[i].ID == cluster.AgentGroupId {
+			return &groups[i], "", nil
+		}
+	}
+	return nil, "", fmt.Errorf("cluster %q has no agent group", cluster.ClusterName)
+}
+
+// GetAgentGroup fetches an agent group with its agents (GET /v1/agent-groups/{id}).
+func GetAgentGroup(ctx context.Context, cfg model.Config, invalidCert bool, id string) (*model.AgentGroupDetail, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/agent-groups/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "GET", endpoint, "", nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	var detail model.AgentGroupDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
+		return nil, string(body), endpoint, fmt.Errorf("failed to parse agent group JSON: %w", err)
+	}
+	return &detail, string(body), endpoint, nil
+}
+
+// ListAgents returns every agent (GET /v1/agents), optionally of a single agent
+// group, together with a merged JSON body and the endpoint.
+func ListAgents(ctx context.Context, cfg model.Config, invalidCert bool, groupID string) ([]model.Agent, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/agents"
+	query := url.Values{}
+	if groupID != "" {
+		query.Set("agentGroupId", groupID)
+	}
+	items, total, body, err := listAll(ctx, client, endpoint, query, agentPageSize, "agents", func(r *model.AgentsResponse) ([]model.Agent, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+
+	merged, err := json.Marshal(model.AgentsResponse{Total: total, Page: 1, Items: items})
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return items, string(merged), endpoint, nil
+}
+
+// HeartbeatAge returns how long ago an RFC 3339 heartbeat timestamp was; ok is
+// false when the timestamp is missing or invalid.
+func HeartbeatAge(heartbeat string, now time.Time) (time.Duration, bool) {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(heartbeat))
+	if err != nil {
+		return 0, false
+	}
+	if d := now.Sub(t); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// StaleAgents returns the agents whose last heartbeat is older than after, or
+// unknown: an agent that never reported is the first thing to look at.
+func StaleAgents(agents []model.Agent, after time.Duration, now time.Time) []model.Agent {
+	stale := []model.Agent{}
+	for _, a := range agents {
+		if age, ok := HeartbeatAge(a.LastHeartbeat, now); !ok || age > after {
+			stale = append(stale, a)
+		}
+	}
+	return stale
+}
+
+// FormatAge renders a duration the way kubectl does, e.g. "45s", "12m", "3h", "5d".
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// AgentVersions counts agents per version, e.g. "1.2.0 (3), 1.1.0 (1)", most common first.
+func AgentVersions(agents []model.Agent) string {
+	counts := map[string]int{}
+	for _, a := range agents {
+		v := a.Version
+		if v == "" {
+			v = "unknown"
+		}
+		counts[v]++
+	}
+	versions := make([]string, 0, len(counts))
+	for v := range counts {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if counts[versions[i]] != counts[versions[j]] {
+			return counts[versions[i]] > counts[versions[j]]
+		}
+		return versions[i] > versions[j]
+	})
+	parts := make([]string, len(versions))
+	for i, v := range versions {
+		parts[i] = fmt.Sprintf("%s (%d)", v, counts[v])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,111 +1,144 @@
 package controller
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/arturscheiner/kcskit/internal/model"
-	"github.com/charmbracelet/glamour"
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
 )
 
-func SendToOllama(jsonOutput string, header model.OllamaHeader) (string, error) {
-	cfg, err := LoadConfig()
-	if err != nil {
-		return "", fmt.Errorf("failed to load config: %w", err)
-	}
+// AIProviders lists the supported values of ai_provider.
+var AIProviders = cfgsvc.AIProviders
 
-	if cfg.AiOllamaEndpoint == "" || cfg.AiOllamaModel == "" {
-		return "", fmt.Errorf("ollama endpoint or model not configured")
-	}
+// NewAIProvider returns the AI backend selected by the configuration
+// (ai_provider: ollama or an OpenAI-compatible API).
+func NewAIProvider(cfg model.Config) (cfgsvc.AIProvider, error) {
+	return cfgsvc.NewAIProvider(cfg)
+}
 
-	endpoint := strings.TrimSuffix(cfg.AiOllamaEndpoint, "/")
+// ReportWriter receives an AI report while it is streamed.
+type ReportWriter interface {
+	// WriteHeader receives the report header before the model is asked.
+	WriteHeader(markdown string)
+	// WriteChunk receives every piece of the answer as it arrives.
+	WriteChunk(chunk string)
+}
 
-	promptContent := fmt.Sprintf("You are an expert on Kaspersky Container Security. You are using a command line utility called kcskit and you have executed the command '%s' that calls the kcs api %s .Evaluate its output and give some insights about this: %s", header.Command, header.ApiEndpoint, jsonOutput)
+// SendToAI asks the AI provider to evaluate jsonOutput with the prompt
+// template and streams the answer to w (when not nil). The output is trimmed
+// to the fields relevant for the report; when it still exceeds the model's
+// context window it is summarised in chunks first (see summariseOutput). It
+// returns the complete Markdown report and the conversation, which
+// NewReportChatSession can continue.
+func SendToAI(ctx context.Context, provider cfgsvc.AIProvider, tmpl *PromptTemplate, jsonOutput string, header model.OllamaHeader, w ReportWriter) (string, []model.Message, error) {
+	started := time.Now()
+	c := newTokenCounter(provider)
+	data := PromptData{
+		OllamaHeader: header,
+		Name:         tmpl.Name,
+		Model:        provider.Model(),
+		Date:         started.Format(time.RFC1123),
+	}
 
-	var tokenCount int
-	tokenizeReqBody := model.TokenizeRequest{
-		Model:   cfg.AiOllamaModel,
-		Content: promptContent,
+	window := contextWindow(ctx, provider)
+	// a quarter of the window is kept for the answer, the prompt itself takes its
+	// share (templates that need the output fail here and are checked below)
+	system, prompt, _ := renderPrompt(tmpl, data)
+	tb := TokenBudget{ContextLength: window, Available: window - window/4 - c.estimate(system+prompt)}
+	if tb.Available < minOutputTokens {
+		return "", nil, fmt.Errorf("the context window of %d tokens is too small for a report (set ai_context_length)", window)
 	}
-	jsonTokenizeBody, err := json.Marshal(tokenizeReqBody)
-	if err != nil {
-		fmt.Printf("failed to marshal tokenize request body: %v\n", err)
-	} else {
-		resp, err := http.Post(fmt.Sprintf("%s/api/tokenize", endpoint), "application/json", bytes.NewBuffer(jsonTokenizeBody))
+
+	output := TrimJSON(jsonOutput)
+	tb.TrimmedTokens = c.count(ctx, output)
+	tb.RawTokens = tb.TrimmedTokens
+	if len(output) != len(jsonOutput) {
+		tb.RawTokens = c.count(ctx, jsonOutput)
+	}
+	if tb.TrimmedTokens > tb.Available {
+		progress, _ := w.(ReportStatus)
+		summaries, err := summariseOutput(ctx, provider, c, header.Command, output, tb.Available, progress, &tb)
 		if err != nil {
-			fmt.Printf("failed to send request to ollama for tokenization: %v\n", err)
-		} else {
-			defer resp.Body.Close()
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				fmt.Printf("failed to read tokenize response body: %v\n", err)
-			} else {
-				var tokenizeResponse model.TokenizeResponse
-				if err := json.Unmarshal(body, &tokenizeResponse); err != nil {
-					fmt.Printf("failed to unmarshal ollama tokenize response: %v\n", err)
-					fmt.Printf("Ollama tokenize response body: %s\n", string(body))
-				} else {
-					tokenCount = len(tokenizeResponse.Tokens)
-				}
-			}
+			return "", nil, err
+		}
+		tb.SummaryTokens = c.estimate(summaries)
+		data.Summarised, data.Parts, data.Summaries = true, tb.Chunks, summaries
+	} else {
+		data.JSON, data.RawJSON = output, jsonOutput
+		if err := json.Unmarshal([]byte(output), &data.Data); err == nil {
+			_, data.Items = outputItems(data.Data)
 		}
 	}
-
-	requestBody := model.OllamaRequest{
-		Model: cfg.AiOllamaModel,
-		Messages: []model.Message{
-			{
-				Role:    "user",
-				Content: promptContent,
-			},
-		},
-		Stream: false,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
+	tb.Estimated = c.estimated
+	data.Budget = tb.String()
+	system, prompt, err := renderPrompt(tmpl, data)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
+		return "", nil, err
 	}
 
-	resp, err := http.Post(fmt.Sprintf("%s/api/chat", endpoint), "application/json", bytes.NewBuffer(jsonBody))
+	// not every backend can count tokens; the count reported with the answer is used instead
+	tokenCount, _ := provider.CountTokens(ctx, system+prompt)
+	data.InputTokens = inputTokens(tokenCount)
+	// templates can add more than planned for, e.g. .RawJSON or {{json .Data}} besides .JSON
+	promptTokens := tokenCount
+	if promptTokens == 0 {
+		promptTokens = c.estimate(system + prompt)
+	}
+	if limit := window - window/4; promptTokens > limit {
+		return "", nil, fmt.Errorf("the rendered prompt template %s takes %d tokens, more than the %d the context window of %d tokens leaves besides the answer; use .JSON or .Summaries instead of .RawJSON or .Data, or set ai_context_length", tmpl.Name, promptTokens, limit, window)
+	}
+	reportHeader, err := tmpl.Render("header", data)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request to ollama: %w", err)
+		return "", nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	var onChunk func(string)
+	if w != nil {
+		w.WriteHeader(reportHeader)
+		onChunk = w.WriteChunk
+	}
+	var messages []model.Message
+	if strings.TrimSpace(system) != "" {
+		messages = append(messages, model.Message{Role: "system", Content: system})
+	}
+	messages = append(messages, model.Message{Role: "user", Content: prompt})
+	reply, err := provider.Chat(ctx, messages, onChunk)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", nil, err
 	}
+	messages = append(messages, model.Message{Role: "assistant", Content: reply.Content})
 
-	var ollamaResponse model.OllamaResponse
-	if err := json.Unmarshal(body, &ollamaResponse); err != nil {
-		return "", fmt.Errorf("failed to unmarshal ollama response: %w", err)
+	if tokenCount == 0 && reply.PromptTokens > 0 {
+		data.Model, data.InputTokens = reply.Model, inputTokens(reply.PromptTokens)
+		if reportHeader, err = tmpl.Render("header", data); err != nil {
+			return "", nil, err
+		}
 	}
+	return reportHeader + reply.Content, messages, nil
+}
 
-	if tokenCount == 0 {
-		tokenCount = ollamaResponse.PromptEvalCount
+// renderPrompt renders the system prompt and the request of a report.
+func renderPrompt(tmpl *PromptTemplate, data PromptData) (string, string, error) {
+	system, err := tmpl.Render("system", data)
+	if err != nil {
+		return "", "", err
 	}
-
-	headerString := fmt.Sprintf(
-		"# %s\n\n**Command line:** `%s`\n\n**Date and Time:** %s\n\n**Risk Status Summary:** %s\n\n**Purpose of the Report:** Automated security status and recommendations.\n\n**Model:** %s\n\n**Input Tokens:** %d\n\n---\n\n",
-	header.ReportTitle,
-	header.Command,
-	time.Now().Format(time.RFC1123),
-	header.Risk,
-	ollamaResponse.Model,
-	tokenCount,
-	)
-
-	out, err := glamour.Render(headerString+ollamaResponse.Message.Content, "dark")
+	prompt, err := tmpl.Render("prompt", data)
 	if err != nil {
-		return "", fmt.Errorf("failed to render markdown: %w", err)
+		return "", "", err
 	}
+	return system, prompt, nil
+}
 
-	return out, nil
+// inputTokens formats a token count for the report header; 0 means unknown.
+func inputTokens(n int) string {
+	if n > 0 {
+		return strconv.Itoa(n)
+	}
+	return "unknown"
 }
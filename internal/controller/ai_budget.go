@@ -0,0 +1,362 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
+)
+
+const (
+	// charsPerToken estimates token counts when the provider cannot count them.
+	charsPerToken = 4
+	// aiMaxString truncates long strings (descriptions, manifests) in trimmed JSON.
+	aiMaxString = 300
+	// minOutputTokens is the smallest budget for the command output a report is tried with.
+	minOutputTokens = 256
+	// maxMergeRounds bounds the reduce phase of a map-reduce summary.
+	maxMergeRounds = 3
+)
+
+// aiDroppedFields are response fields that cost many tokens and add little to
+// a security assessment, such as package inventories and image layers.
+var aiDroppedFields = map[string]bool{
+	"packages": true,
+	"layers":   true,
+	"history":  true,
+	"links":    true,
+	"_links":   true,
+	"icon":     true,
+}
+
+// TokenBudget describes how an AI report was fitted into the model's context window.
+type TokenBudget struct {
+	ContextLength int // context window in tokens
+	Available     int // tokens left for the command output
+	RawTokens     int // tokens of the JSON as returned by KCS
+	TrimmedTokens int // tokens of the trimmed JSON
+	Chunks        int // parts the output was summarised in (0: sent as is)
+	MergeRounds   int // rounds merging the part summaries
+	SummaryTokens int // tokens of the merged summaries
+	Estimated     bool
+}
+
+// String returns the budget as one line for the report header.
+func (b TokenBudget) String() string {
+	approx := ""
+	if b.Estimated {
+		approx = "≈"
+	}
+	s := fmt.Sprintf("context window %d tokens, %d available for the output; output %s%d tokens", b.ContextLength, b.Available, approx, b.TrimmedTokens)
+	if b.RawTokens > b.TrimmedTokens {
+		s += fmt.Sprintf(" (trimmed from %s%d)", approx, b.RawTokens)
+	}
+	if b.Chunks > 0 {
+		s += fmt.Sprintf(", summarised in %d chunks", b.Chunks)
+		if b.MergeRounds > 0 {
+			s += fmt.Sprintf(" and %d merge rounds", b.MergeRounds)
+		}
+		s += fmt.Sprintf(" to %s%d tokens", approx, b.SummaryTokens)
+	}
+	return s
+}
+
+// tokenCounter counts tokens with the provider's tokenizer where possible and
+// otherwise estimates them from the text length, calibrated on the last count.
+type tokenCounter struct {
+	provider      cfgsvc.AIProvider
+	charsPerToken float64
+	estimated     bool
+}
+
+func newTokenCounter(provider cfgsvc.AIProvider) *tokenCounter {
+	return &tokenCounter{provider: provider, charsPerToken: charsPerToken}
+}
+
+// count counts the tokens of text with the tokenizer.
+func (c *tokenCounter) count(ctx context.Context, text string) int {
+	n, err := c.provider.CountTokens(ctx, text)
+	if err != nil || n == 0 {
+		c.estimated = true
+		return c.estimate(text)
+	}
+	c.charsPerToken = float64(len(text)) / float64(n)
+	return n
+}
+
+// estimate estimates the tokens of text without calling the provider.
+func (c *tokenCounter) estimate(text string) int {
+	return int(float64(len(text))/c.charsPerToken) + 1
+}
+
+// contextWindow returns the context window of the provider's model. When the
+// backend cannot tell (e.g. /api/show is blocked by a proxy), the provider
+// falls back to DefaultContextLength, which is then planned for.
+func contextWindow(ctx context.Context, provider cfgsvc.AIProvider) int {
+	n, err := provider.ContextLength(ctx)
+	if err != nil || n <= 0 {
+		return cfgsvc.DefaultContextLength
+	}
+	return n
+}
+
+// messagesTokens estimates the tokens of messages, including a few per message
+// for the chat format.
+func messagesTokens(c *tokenCounter, messages []model.Message) int {
+	n := 0
+	for _, m := range messages {
+		n += c.estimate(m.Content) + 4
+	}
+	return n
+}
+
+// TrimJSON removes what an AI report does not need from a JSON response: null,
+// empty values and aiDroppedFields, and cuts long strings. The result is
+// compact JSON; text that is not JSON is returned unchanged.
+func TrimJSON(body string) string {
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	v, _ = trimValue(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
+
+// trimValue trims v and reports whether it is worth keeping.
+func trimValue(v any) (any, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case string:
+		if v == "" {
+			return nil, false
+		}
+		if utf8.RuneCountInString(v) > aiMaxString {
+			return string([]rune(v)[:aiMaxString]) + "…", true
+		}
+		return v, true
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, e := range v {
+			if e, ok := trimValue(e); ok {
+				out = append(out, e)
+			}
+		}
+		return out, len(out) > 0
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			if aiDroppedFields[strings.ToLower(k)] {
+				continue
+			}
+			if e, ok := trimValue(e); ok {
+				out[k] = e
+			}
+		}
+		return out, len(out) > 0
+	}
+	return v, true
+}
+
+// outputItems finds the list of items in parsed JSON: the document itself when
+// it is an array, else its "items" field or its longest array field (key).
+func outputItems(v any) (key string, items []any) {
+	switch v := v.(type) {
+	case []any:
+		return "", v
+	case map[string]any:
+		if l, ok := v["items"].([]any); ok {
+			return "items", l
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if l, ok := v[k].([]any); ok && len(l) > len(items) {
+				key, items = k, l
+			}
+		}
+	}
+	return key, items
+}
+
+// splitItems splits trimmed JSON into its items (see outputItems) and meta,
+// the rest of the document, which is repeated in every chunk.
+func splitItems(trimmed string) (meta string, items []string) {
+	var v any
+	if err := json.Unmarshal([]byte(trimmed), &v); err != nil {
+		return "", nil
+	}
+	key, list := outputItems(v)
+	if m, ok := v.(map[string]any); ok {
+		if key == "" {
+			return "", nil
+		}
+		rest := make(map[string]any, len(m))
+		for k, e := range m {
+			if k != key {
+				rest[k] = e
+			}
+		}
+		if len(rest) > 0 {
+			b, _ := json.Marshal(rest)
+			meta = string(b)
+		}
+	}
+	for _, e := range list {
+		b, _ := json.Marshal(e)
+		items = append(items, string(b))
+	}
+	return meta, items
+}
+
+// packItems groups items into runs of at most budget tokens; an item larger
+// than budget forms a group of its own.
+func packItems(c *tokenCounter, items []string, budget int) [][]string {
+	var groups [][]string
+	var cur []string
+	curTokens := 0
+	for _, item := range items {
+		n := c.estimate(item) + 1
+		if len(cur) > 0 && curTokens+n > budget {
+			groups = append(groups, cur)
+			cur, curTokens = nil, 0
+		}
+		cur = append(cur, item)
+		curTokens += n
+	}
+	if len(cur) > 0 {
+		groups = append(groups, cur)
+	}
+	return groups
+}
+
+// chunkItems packs JSON items into arrays of at most budget tokens. An item
+// larger than budget is split as text.
+func chunkItems(c *tokenCounter, items []string, budget int) []string {
+	var chunks []string
+	for _, g := range packItems(c, items, budget) {
+		if len(g) == 1 && c.estimate(g[0]) > budget {
+			chunks = append(chunks, chunkText(c, g[0], budget)...)
+			continue
+		}
+		chunks = append(chunks, "["+strings.Join(g, ",")+"]")
+	}
+	return chunks
+}
+
+// chunkText splits text into pieces of at most budget tokens.
+func chunkText(c *tokenCounter, text string, budget int) []string {
+	size := max(int(float64(budget)*c.charsPerToken), 1)
+	var chunks []string
+	for len(text) > 0 {
+		n := min(size, len(text))
+		for n < len(text) && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		if n == 0 {
+			n = min(size, len(text))
+		}
+		chunks = append(chunks, text[:n])
+		text = text[n:]
+	}
+	return chunks
+}
+
+// ReportStatus is implemented by report writers that show progress while
+// large outputs are summarised.
+type ReportStatus interface {
+	Status(text string)
+}
+
+// summariseOutput reduces output to at most budget tokens: it is split into
+// chunks (by item when it is a list), each chunk is summarised by the model
+// and the summaries are merged until they fit. Progress is shown on
+// progress, if not nil.
+func summariseOutput(ctx context.Context, provider cfgsvc.AIProvider, c *tokenCounter, command, output string, budget int, progress ReportStatus, tb *TokenBudget) (string, error) {
+	status := func(format string, args ...any) {
+		if progress != nil {
+			progress.Status(fmt.Sprintf(format, args...))
+		}
+	}
+
+	// each chunk also holds the metadata and the summary prompt, keep room for both
+	meta, items := splitItems(output)
+	chunkBudget := budget - c.estimate(meta) - c.estimate(summaryPrompt(command, 1, 1, "", ""))
+	var chunks []string
+	if len(items) > 0 && chunkBudget > budget/4 {
+		chunks = chunkItems(c, items, chunkBudget)
+	} else {
+		meta = ""
+		chunks = chunkText(c, output, max(budget-c.estimate(summaryPrompt(command, 1, 1, "", "")), budget/2))
+	}
+	tb.Chunks = len(chunks)
+
+	summaries := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		status("%s: summarising part %d/%d", provider.Model(), i+1, len(chunks))
+		reply, err := provider.Chat(ctx, []model.Message{{Role: "user", Content: summaryPrompt(command, i+1, len(chunks), meta, chunk)}}, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarise part %d/%d: %w", i+1, len(chunks), err)
+		}
+		summaries[i] = strings.TrimSpace(reply.Content)
+	}
+
+	merged := joinSummaries(summaries)
+	for round := 1; c.estimate(merged) > budget && len(summaries) > 1 && round <= maxMergeRounds; round++ {
+		groups := packItems(c, summaries, budget/2)
+		if len(groups) >= len(summaries) {
+			break
+		}
+		next := make([]string, len(groups))
+		for i, g := range groups {
+			status("%s: merging summaries %d/%d", provider.Model(), i+1, len(groups))
+			reply, err := provider.Chat(ctx, []model.Message{{Role: "user", Content: mergePrompt(command, joinSummaries(g))}}, nil)
+			if err != nil {
+				return "", fmt.Errorf("failed to merge summaries: %w", err)
+			}
+			next[i] = strings.TrimSpace(reply.Content)
+		}
+		summaries = next
+		merged = joinSummaries(summaries)
+		tb.MergeRounds = round
+	}
+	if n := c.estimate(merged); n > budget {
+		// still too large: keep what fits rather than overflowing the context
+		merged = chunkText(c, merged, budget)[0]
+	}
+	status("%s", provider.Model())
+	return merged, nil
+}
+
+// joinSummaries numbers the summaries of the parts of an output.
+func joinSummaries(summaries []string) string {
+	var sb strings.Builder
+	for i, s := range summaries {
+		fmt.Fprintf(&sb, "### Part %d of %d\n\n%s\n\n", i+1, len(summaries), s)
+	}
+	return sb.String()
+}
+
+func summaryPrompt(command string, part, parts int, meta, chunk string) string {
+	p := fmt.Sprintf("You are an expert on Kaspersky Container Security. The output of the kcskit command '%s' is too large to evaluate at once, so it is split into %d parts. Summarise part %d in a few concise bullet points for a later security assessment: keep names, IDs, risk ratings, severities, counts and anything unusual, and leave out recommendations.", command, parts, part)
+	if meta != "" {
+		p += "\n\nFields shared by all parts: " + meta
+	}
+	return p + "\n\nPart " + fmt.Sprint(part) + ": " + chunk
+}
+
+func mergePrompt(command, summaries string) string {
+	return fmt.Sprintf("You are an expert on Kaspersky Container Security. These are summaries of parts of the output of the kcskit command '%s'. Merge them into one concise summary in bullet points, keeping names, IDs, risk ratings, severities and counts (add counts up where they overlap).\n\n%s", command, summaries)
+}
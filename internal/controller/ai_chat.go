@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
+)
+
+// chatSystemPrompt introduces a chat session; the command output, if any, follows it.
+const chatSystemPrompt = "You are an expert on Kaspersky Container Security. The user runs kcskit, a command line utility for the KCS API, and asks questions about its results. Answer in Markdown, based on the data provided, and say so when the data does not contain the answer."
+
+// ChatSession is a multi-turn conversation with an AI provider. The message
+// history is sent with every question, so follow-ups can refer to earlier
+// answers and to the command output given as context. When the history no
+// longer fits in the context window, the oldest questions are left out.
+type ChatSession struct {
+	provider cfgsvc.AIProvider
+	path     string
+	counter  *tokenCounter
+	window   int
+
+	Transcript model.ChatTranscript
+	// Dropped is the number of earlier questions left out of the last request
+	// because the history did not fit in the context window.
+	Dropped int
+}
+
+// NewChatSession starts a session about the output of command (both may be
+// empty for a session without context). JSON output is trimmed like for
+// reports; output that takes more than half of the context window is
+// summarised first (see summariseOutput), with progress shown on progress (if
+// not nil).
+func NewChatSession(ctx context.Context, provider cfgsvc.AIProvider, command, contextOutput string, progress ReportStatus) (*ChatSession, error) {
+	system := chatSystemPrompt
+	if strings.TrimSpace(contextOutput) != "" {
+		c := newTokenCounter(provider)
+		window := contextWindow(ctx, provider)
+		// half of the window is left for the questions and answers
+		available := window/2 - c.estimate(system)
+		if available < minOutputTokens {
+			return nil, fmt.Errorf("the context window of %d tokens is too small for chatting about the data (set ai_context_length)", window)
+		}
+		contextOutput = TrimJSON(contextOutput)
+		label := "Data"
+		if command != "" {
+			label = fmt.Sprintf("Output of the command '%s'", command)
+		}
+		if c.count(ctx, contextOutput) > available {
+			var tb TokenBudget
+			summary, err := summariseOutput(ctx, provider, c, command, contextOutput, available, progress, &tb)
+			if err != nil {
+				return nil, err
+			}
+			contextOutput = summary
+			label = fmt.Sprintf("%s, too large for the context window, summarised in %d parts", label, tb.Chunks)
+		}
+		system += "\n\n" + label + ":\n\n" + contextOutput
+	}
+	return newChatSession(provider, command, []model.Message{{Role: "system", Content: system}}), nil
+}
+
+// NewReportChatSession continues the conversation of an AI report (see SendToAI).
+func NewReportChatSession(provider cfgsvc.AIProvider, command string, messages []model.Message) *ChatSession {
+	return newChatSession(provider, command, messages)
+}
+
+// ResumeChatSession continues a saved transcript; it is saved back to path.
+func ResumeChatSession(provider cfgsvc.AIProvider, path string) (*ChatSession, error) {
+	t, err := cfgsvc.LoadTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &ChatSession{provider: provider, path: path, Transcript: t}
+	s.Transcript.Provider = provider.Name()
+	s.Transcript.Model = provider.Model()
+	s.Transcript.Context = min(max(s.Transcript.Context, 0), len(t.Messages))
+	return s, nil
+}
+
+func newChatSession(provider cfgsvc.AIProvider, command string, messages []model.Message) *ChatSession {
+	now := time.Now()
+	return &ChatSession{
+		provider: provider,
+		Transcript: model.ChatTranscript{
+			Provider: provider.Name(),
+			Model:    provider.Model(),
+			Command:  command,
+			Created:  now,
+			Updated:  now,
+			Context:  len(messages),
+			Messages: messages,
+		},
+	}
+}
+
+// Ask sends question with the history and streams the answer to onChunk (when
+// not nil). The question and answer are added to the history; a failed
+// question is dropped, so it can be asked again. A quarter of the context
+// window is kept for the answer; the oldest questions and answers that do not
+// fit besides are left out of the request (see Dropped), never the context.
+func (s *ChatSession) Ask(ctx context.Context, question string, onChunk func(string)) (string, error) {
+	messages := append(s.Transcript.Messages, model.Message{Role: "user", Content: question})
+	if s.counter == nil {
+		s.counter = newTokenCounter(s.provider)
+		s.window = contextWindow(ctx, s.provider)
+		// calibrate the estimates on the context
+		s.counter.count(ctx, joinMessages(s.Transcript.Messages[:s.Transcript.Context]))
+	}
+	sent, dropped, err := fitHistory(s.counter, messages, s.Transcript.Context, s.window-s.window/4)
+	if err != nil {
+		return "", fmt.Errorf("%w of %d tokens; ask a shorter question or start a new session", err, s.window)
+	}
+	s.Dropped = dropped
+
+	reply, err := s.provider.Chat(ctx, sent, onChunk)
+	if err != nil {
+		return "", err
+	}
+	s.Transcript.Messages = append(messages, model.Message{Role: "assistant", Content: reply.Content})
+	s.Transcript.Updated = time.Now()
+	return reply.Content, nil
+}
+
+// fitHistory returns messages without the oldest questions and answers after
+// the first keep messages (the context), so that they fit in budget tokens,
+// and the number of questions left out. The context and the last message
+// (the new question) are always sent; it fails when they alone do not fit.
+func fitHistory(c *tokenCounter, messages []model.Message, keep, budget int) ([]model.Message, int, error) {
+	head, turns, question := messages[:keep], messages[keep:len(messages)-1], messages[len(messages)-1:]
+	fixed := messagesTokens(c, head) + messagesTokens(c, question)
+	if fixed > budget {
+		return nil, 0, fmt.Errorf("the context and the question do not fit in the context window")
+	}
+	dropped := 0
+	for len(turns) > 0 && fixed+messagesTokens(c, turns) > budget {
+		n := 1
+		if turns[0].Role == "user" && len(turns) > 1 && turns[1].Role == "assistant" {
+			n = 2
+		}
+		if turns[0].Role == "user" {
+			dropped++
+		}
+		turns = turns[n:]
+	}
+	sent := make([]model.Message, 0, len(head)+len(turns)+1)
+	sent = append(sent, head...)
+	sent = append(sent, turns...)
+	return append(sent, question...), dropped, nil
+}
+
+// joinMessages concatenates the contents of messages.
+func joinMessages(messages []model.Message) string {
+	var sb strings.Builder
+	for _, m := range messages {
+		sb.WriteString(m.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Turns returns the number of questions asked in the session, including the
+// ones of a resumed transcript.
+func (s *ChatSession) Turns() int {
+	n := 0
+	for _, m := range s.Transcript.Messages[s.Transcript.Context:] {
+		if m.Role == "user" {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset drops the questions and answers, keeping the context.
+func (s *ChatSession) Reset() {
+	s.Transcript.Messages = s.Transcript.Messages[:s.Transcript.Context:s.Transcript.Context]
+	s.Transcript.Updated = time.Now()
+}
+
+// Save writes the transcript to disk and returns its path. A new session is
+// saved to a new file in the transcripts directory, then always to the same one.
+func (s *ChatSession) Save() (string, error) {
+	if s.path == "" {
+		p, err := cfgsvc.NewTranscriptPath(s.Transcript)
+		if err != nil {
+			return "", err
+		}
+		s.path = p
+	}
+	if err := cfgsvc.SaveTranscript(s.path, s.Transcript); err != nil {
+		return "", fmt.Errorf("failed to save transcript: %w", err)
+	}
+	return s.path, nil
+}
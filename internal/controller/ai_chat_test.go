@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+func TestFitHistory(t *testing.T) {
+	// each message below costs 11 tokens: 24 chars / 4 + 1, plus 4 for the format
+	msg := func(role, name string) model.Message {
+		return model.Message{Role: role, Content: name + strings.Repeat(".", 24-len(name))}
+	}
+	ctx := []model.Message{msg("system", "sys"), msg("user", "context")}
+	history := []model.Message{
+		msg("user", "q1"), msg("assistant", "a1"),
+		msg("user", "q2"), msg("assistant", "a2"),
+		msg("user", "q3"), msg("assistant", "a3"),
+	}
+	question := msg("user", "q4")
+	all := append(append(append([]model.Message{}, ctx...), history...), question)
+
+	names := func(msgs []model.Message) string {
+		var s []string
+		for _, m := range msgs {
+			s = append(s, strings.TrimRight(m.Content, "."))
+		}
+		return strings.Join(s, " ")
+	}
+	tests := []struct {
+		name     string
+		messages []model.Message
+		budget   int
+		want     string
+		dropped  int
+	}{
+		{"everything fits", all, 99, "sys context q1 a1 q2 a2 q3 a3 q4", 0},
+		{"drop oldest turn", all, 98, "sys context q2 a2 q3 a3 q4", 1},
+		{"drop two turns", all, 55, "sys context q3 a3 q4", 2},
+		{"only context and question", all, 33, "sys context q4", 3},
+		{"unanswered question first", append(append([]model.Message{}, ctx...), msg("user", "q1"), msg("user", "q2"), msg("assistant", "a2"), question), 55, "sys context q2 a2 q4", 1},
+		{"no history", append(append([]model.Message{}, ctx...), question), 33, "sys context q4", 0},
+	}
+	c := &tokenCounter{charsPerToken: charsPerToken}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent, dropped, err := fitHistory(c, tt.messages, len(ctx), tt.budget)
+			if err != nil {
+				t.Fatalf("fitHistory: %v", err)
+			}
+			if got := names(sent); got != tt.want || dropped != tt.dropped {
+				t.Errorf("fitHistory() = %q, %d dropped; want %q, %d", got, dropped, tt.want, tt.dropped)
+			}
+		})
+	}
+
+	if _, _, err := fitHistory(c, all, len(ctx), 32); err == nil {
+		t.Error("fitHistory succeeded although the context and question do not fit")
+	}
+}
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// ResourceKinds lists the kinds accepted by apply and diff.
+var ResourceKinds = []string{"Registry"}
+
+// ReadResources reads the KCS resource documents of path: a YAML file, a
+// directory (searched recursively for *.yaml and *.yml) or "-" for stdin.
+// Every document must have a kind.
+func ReadResources(path string) (model.ResourceSet, error) {
+	var set model.ResourceSet
+	if path == "-" {
+		if err := decodeResources(os.Stdin, "", &set); err != nil {
+			return set, fmt.Errorf("stdin: %w", err)
+		}
+		return set, checkDuplicates(set)
+	}
+
+	files, err := yamlFiles(path)
+	if err != nil {
+		return set, err
+	}
+	for _, p := range files {
+		f, err := os.Open(p)
+		if err != nil {
+			return set, err
+		}
+		err = decodeResources(f, "", &set)
+		f.Close()
+		if err != nil {
+			return set, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return set, checkDuplicates(set)
+}
+
+// decodeResources appends the documents of a YAML stream to set. Documents
+// without a kind get defaultKind, or are rejected when it is empty.
+func decodeResources(r io.Reader, defaultKind string, set *model.ResourceSet) error {
+	dec := yaml.NewDecoder(r)
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		var head struct {
+			Kind string `yaml:"kind"`
+		}
+		if err := node.Decode(&head); err != nil {
+			return err
+		}
+		if head.Kind == "" && len(node.Content) == 0 {
+			// empty document
+			continue
+		}
+		if head.Kind == "" {
+			head.Kind = defaultKind
+		}
+
+		switch head.Kind {
+		case "Registry":
+			var spec model.RegistrySpec
+			if err := node.Decode(&spec); err != nil {
+				return err
+			}
+			spec.Kind = "Registry"
+			if err := expandRegistryCredentials(&spec); err != nil {
+				return err
+			}
+			set.Registries = append(set.Registries, spec)
+		case "":
+			return fmt.Errorf("line %d: document without kind (want %s)", node.Line, strings.Join(ResourceKinds, "|"))
+		default:
+			return fmt.Errorf("line %d: unsupported kind %q (want %s)", node.Line, head.Kind, strings.Join(ResourceKinds, "|"))
+		}
+	}
+}
+
+// checkDuplicates rejects resources of the same kind declared twice.
+func checkDuplicates(set model.ResourceSet) error {
+	seen := map[string]bool{}
+	for _, r := range set.Registries {
+		key := strings.ToLower(r.RegistryName)
+		if seen[key] {
+			return fmt.Errorf("registry %q is declared more than once", r.RegistryName)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// PlanRegistries compares the desired registries with the live ones, matched
+// by case-insensitive name. Credentials are write-only in the API, so they are
+// sent with creates and updates but never cause an update on their own. With
+// prune, live registries that are not desired are deleted.
+func PlanRegistries(live []model.RegistryItem, desired []model.RegistrySpec, prune bool) []model.PlanAction {
+	byName := map[string]model.RegistryItem{}
+	for _, it := range live {
+		byName[strings.ToLower(it.RegistryName)] = it
+	}
+
+	var plan []model.PlanAction
+	wanted := map[string]bool{}
+	for i := range desired {
+		spec := &desired[i]
+		key := strings.ToLower(spec.RegistryName)
+		wanted[key] = true
+		it, ok := byName[key]
+		if !ok {
+			plan = append(plan, model.PlanAction{Action: "create", Kind: "Registry", Name: spec.RegistryName, Registry: spec})
+			continue
+		}
+		changes := registryChanges(it, *spec)
+		action := "unchanged"
+		if len(changes) > 0 {
+			action = "update"
+		}
+		plan = append(plan, model.PlanAction{Action: action, Kind: "Registry", Name: spec.RegistryName, ID: it.ID, Changes: changes, Registry: spec})
+	}
+
+	if prune {
+		for _, it := range live {
+			if !wanted[strings.ToLower(it.RegistryName)] {
+				plan = append(plan, model.PlanAction{Action: "delete", Kind: "Registry", Name: it.RegistryName, ID: it.ID})
+			}
+		}
+	}
+	return plan
+}
+
+// registryChanges lists the fields of live that differ from spec.
+func registryChanges(live model.RegistryItem, spec model.RegistrySpec) []model.FieldChange {
+	var changes []model.FieldChange
+	cmp := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, model.FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	cmp("name", live.RegistryName, spec.RegistryName)
+	cmp("type", live.RegistryType, spec.RegistryType)
+	cmp("url", live.RegistryUrl, spec.RegistryUrl)
+	cmp("apiUrl", live.ApiUrl, spec.ApiUrl)
+	cmp("description", live.Description, spec.Description)
+	cmp("auth.type", live.AuthenticationType, spec.AuthenticationType)
+	return changes
+}
+
+// Plan computes the actions needed to reach set. Pruning only considers kinds
+// that appear in set, so an empty spec directory never deletes everything.
+func Plan(ctx context.Context, cfg model.Config, invalidCert bool, set model.ResourceSet, prune bool) ([]model.PlanAction, string, error) {
+	for _, spec := range set.Registries {
+		if err := ValidateRegistrySpec(spec, false); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var plan []model.PlanAction
+	if len(set.Registries) > 0 {
+		live, body, _, err := ListRegistries(ctx, cfg, invalidCert)
+		if err != nil {
+			return nil, body, err
+		}
+		plan = append(plan, PlanRegistries(live, set.Registries, prune)...)
+	}
+	for _, a := range plan {
+		if a.Action == "create" && a.Registry != nil {
+			// new registries need their credentials
+			if err := ValidateRegistrySpec(*a.Registry, true); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return plan, "", nil
+}
+
+// PlanChanged reports whether plan contains anything but unchanged resources.
+func PlanChanged(plan []model.PlanAction) bool {
+	for _, a := range plan {
+		if a.Action != "unchanged" {
+			return true
+		}
+	}
+	return false
+}
+
+// appliedResults is the Result of a successful action.
+var appliedResults = map[string]string{"create": "created", "update": "updated", "delete": "deleted"}
+
+// ApplyPlan executes the create, update and delete actions of plan in order,
+// recording the outcome in each action's Result. It continues after failures
+// and returns the number of failed actions.
+func ApplyPlan(ctx context.Context, cfg model.Config, invalidCert bool, plan []model.PlanAction) int {
+	failed := 0
+	for i := range plan {
+		a := &plan[i]
+		var body string
+		var err error
+		switch a.Action {
+		case "create":
+			var item *model.RegistryItem
+			item, body, _, err = CreateRegistry(ctx, cfg, invalidCert, *a.Registry)
+			if err == nil {
+				a.ID = item.ID
+			}
+		case "update":
+			_, body, _, err = UpdateRegistry(ctx, cfg, invalidCert, a.ID, *a.Registry)
+		case "delete":
+			body, _, err = DeleteRegistry(ctx, cfg, invalidCert, a.ID)
+		default:
+			continue
+		}
+		if err != nil {
+			failed++
+			a.Result = "failed: " + errorWithBody(err, body)
+			continue
+		}
+		a.Result = appliedResults[a.Action]
+	}
+	return failed
+}
+
+// SummarizePlan counts the actions of plan, e.g. "1 to create, 0 to update, 0 to delete, 2 unchanged".
+func SummarizePlan(plan []model.PlanAction) string {
+	counts := map[string]int{}
+	for _, a := range plan {
+		counts[a.Action]++
+	}
+	return fmt.Sprintf("%d to create, %d to update, %d to delete, %d unchanged", counts["create"], counts["update"], counts["delete"], counts["unchanged"])
+}
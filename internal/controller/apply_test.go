@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+func TestPlanRegistries(t *testing.T) {
+	live := []model.RegistryItem{
+		{ID: "r1", RegistryName: "Harbor", RegistryType: "harbor", RegistryUrl: "https://harbor.local", AuthenticationType: "basic"},
+		{ID: "r2", RegistryName: "gitlab", RegistryType: "gitlab", RegistryUrl: "https://gitlab.local", AuthenticationType: "token"},
+		{ID: "r3", RegistryName: "old", RegistryType: "nexus", RegistryUrl: "https://nexus.local", AuthenticationType: "none"},
+	}
+	spec := func(name, typ, url, auth string) model.RegistrySpec {
+		return model.RegistrySpec{Kind: "Registry", RegistryName: name, RegistryType: typ, RegistryUrl: url, RegistryAuth: model.RegistryAuth{AuthenticationType: auth}}
+	}
+	withPassword := spec("Harbor", "harbor", "https://harbor.local", "basic")
+	withPassword.Password = "new"
+	type action struct{ Action, Name, ID, Changes string }
+	tests := []struct {
+		name    string
+		desired []model.RegistrySpec
+		prune   bool
+		want    []action
+	}{
+		{"credentials only", []model.RegistrySpec{withPassword}, false, []action{
+			{"unchanged", "Harbor", "r1", ""},
+		}},
+		{"name case", []model.RegistrySpec{spec("harbor", "harbor", "https://harbor.local", "basic")}, false, []action{
+			{"update", "harbor", "r1", "name"},
+		}},
+		{"update and create", []model.RegistrySpec{
+			spec("gitlab", "gitlab", "https://gitlab.example", "basic"),
+			spec("ecr", "ecr", "https://1234.dkr.ecr.aws", "aws"),
+		}, false, []action{
+			{"update", "gitlab", "r2", "url auth.type"},
+			{"create", "ecr", "", ""},
+		}},
+		{"prune", []model.RegistrySpec{spec("Harbor", "harbor", "https://harbor.local", "basic")}, true, []action{
+			{"unchanged", "Harbor", "r1", ""},
+			{"delete", "gitlab", "r2", ""},
+			{"delete", "old", "r3", ""},
+		}},
+		{"prune nothing desired", nil, true, []action{
+			{"delete", "Harbor", "r1", ""},
+			{"delete", "gitlab", "r2", ""},
+			{"delete", "old", "r3", ""},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []action
+			for _, a := range PlanRegistries(live, tt.desired, tt.prune) {
+				var fields []string
+				for _, c := range a.Changes {
+					fields = append(fields, c.Field)
+				}
+				got = append(got, action{a.Action, a.Name, a.ID, strings.Join(fields, " ")})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanRegistries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
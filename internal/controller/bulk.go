@@ -0,0 +1,285 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
+)
+
+// ReadScanRefs reads one artifact reference per line, skipping blank lines and
+// lines starting with '#'.
+func ReadScanRefs(r io.Reader) ([]string, error) {
+	var refs []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	return refs, sc.Err()
+}
+
+// NormalizeArtifact appends ":latest" to references without a tag or digest.
+func NormalizeArtifact(artifact string) string {
+	if strings.Contains(artifact, "@") {
+		return artifact
+	}
+	name := artifact
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if strings.Contains(name, ":") {
+		return artifact
+	}
+	return artifact + ":latest"
+}
+
+// RegistryResolver maps the registry part of image references to KCS registries.
+type RegistryResolver struct {
+	byHost map[string]model.RegistryItem
+	byName map[string]model.RegistryItem
+	byID   map[string]model.RegistryItem
+}
+
+// NewRegistryResolver indexes registries by the host of their RegistryUrl, their name and their ID.
+func NewRegistryResolver(registries []model.RegistryItem) *RegistryResolver {
+	r := &RegistryResolver{
+		byHost: map[string]model.RegistryItem{},
+		byName: map[string]model.RegistryItem{},
+		byID:   map[string]model.RegistryItem{},
+	}
+	for _, reg := range registries {
+		if host := registryHost(reg.RegistryUrl); host != "" {
+			r.byHost[host] = reg
+		}
+		if reg.RegistryName != "" {
+			r.byName[strings.ToLower(reg.RegistryName)] = reg
+		}
+		r.byID[reg.ID] = reg
+	}
+	return r
+}
+
+// registryHost returns the lower-cased host[:port] of a registry URL ("https://harbor.local/" -> "harbor.local").
+func registryHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// registry returns the registry whose URL host, name or ID is prefix, in that order.
+func (r *RegistryResolver) registry(prefix string) (model.RegistryItem, bool) {
+	if reg, found := r.byHost[strings.ToLower(prefix)]; found {
+		return reg, true
+	}
+	if reg, found := r.byName[strings.ToLower(prefix)]; found {
+		return reg, true
+	}
+	reg, found := r.byID[prefix]
+	return reg, found
+}
+
+// Resolve splits ref ("<registry>/<artifact>:<tag>") into a scan target. The
+// first path segment is matched against registry hosts, names and IDs, in that
+// order. Images on Docker Hub ("nginx", "library/nginx") resolve to a registry
+// whose URL host is docker.io, registry-1.docker.io or index.docker.io.
+func (r *RegistryResolver) Resolve(ref string) (model.ScanTarget, error) {
+	t := model.ScanTarget{Ref: ref}
+	first, rest, ok := strings.Cut(ref, "/")
+	if ok {
+		if reg, found := r.registry(first); found {
+			t.RegistryID, t.RegistryName, t.Artifact = reg.ID, reg.RegistryName, NormalizeArtifact(rest)
+			return t, nil
+		}
+	}
+	// no registry prefix (or an unknown one that looks like a repository path): try Docker Hub
+	if !ok || !strings.ContainsAny(first, ".:") && first != "localhost" {
+		for _, host := range []string{"docker.io", "registry-1.docker.io", "index.docker.io"} {
+			if reg, found := r.byHost[host]; found {
+				t.RegistryID, t.RegistryName, t.Artifact = reg.ID, reg.RegistryName, NormalizeArtifact(ref)
+				return t, nil
+			}
+		}
+	}
+	if !ok {
+		return t, fmt.Errorf("no registry in %q (want <registry>/<artifact>:<tag>)", ref)
+	}
+	return t, fmt.Errorf("unknown registry %q", first)
+}
+
+// BulkScanOptions controls how BulkScan submits jobs.
+type BulkScanOptions struct {
+	// Concurrency is the number of jobs submitted (and waited for) in parallel.
+	Concurrency int
+	// Rate limits job submissions per second across all workers; 0 means unlimited.
+	Rate float64
+	// Wait polls every submitted job every PollInterval until it finishes.
+	Wait         bool
+	PollInterval time.Duration
+}
+
+// BulkScan submits a scan job for every target. Failures are recorded in the
+// result of the affected target and do not stop the batch; progress, when not
+// nil, is called (serially) as each result is known. Results keep the order of targets.
+func BulkScan(ctx context.Context, cfg model.Config, invalidCert bool, targets []model.ScanTarget, opts BulkScanOptions, progress func(model.BulkScanResult)) []model.BulkScanResult {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	limiter := cfgsvc.NewRateLimiter(opts.Rate)
+	defer limiter.Stop()
+
+	results := make([]model.BulkScanResult, len(targets))
+	// the artifact names of the finished jobs, looked up together once all are done
+	artifacts := make([]string, len(targets))
+	var mu sync.Mutex
+	report := func(i int, res model.BulkScanResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = res
+		if progress != nil {
+			progress(res)
+		}
+	}
+
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				var res model.BulkScanResult
+				res, artifacts[i] = scanTarget(ctx, cfg, invalidCert, targets[i], opts, limiter)
+				report(i, res)
+			}
+		}()
+	}
+	for i := range targets {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+	if opts.Wait {
+		addRiskRatings(ctx, cfg, invalidCert, targets, artifacts, results)
+	}
+	return results
+}
+
+// addRiskRatings sets the risk rating of the scanned artifacts, walking the
+// images of each registry once for all of its artifacts.
+func addRiskRatings(ctx context.Context, cfg model.Config, invalidCert bool, targets []model.ScanTarget, artifacts []string, results []model.BulkScanResult) {
+	byRegistry := map[string][]int{}
+	for i, artifact := range artifacts {
+		if artifact != "" {
+			byRegistry[targets[i].RegistryID] = append(byRegistry[targets[i].RegistryID], i)
+		}
+	}
+	for registryID, indexes := range byRegistry {
+		names := make([]string, len(indexes))
+		for j, i := range indexes {
+			names[j] = artifacts[i]
+		}
+		images, body, err := FindImageResults(ctx, cfg, invalidCert, registryID, names)
+		for _, i := range indexes {
+			if err != nil {
+				results[i].Error = errorWithBody(err, body)
+			} else if image, ok := images[artifacts[i]]; ok {
+				results[i].RiskRating = image.RiskRating
+			}
+		}
+	}
+}
+
+// scanTarget submits (and optionally waits for) the scan of a single target.
+// It also returns the artifact name of a job that finished without failing.
+func scanTarget(ctx context.Context, cfg model.Config, invalidCert bool, t model.ScanTarget, opts BulkScanOptions, limiter *cfgsvc.RateLimiter) (model.BulkScanResult, string) {
+	res := model.BulkScanResult{Ref: t.Ref, Registry: t.RegistryName, Artifact: t.Artifact}
+	if err := limiter.Wait(ctx); err != nil {
+		res.Status, res.Error = "error", err.Error()
+		return res, ""
+	}
+	job, body, _, err := CreateScan(ctx, cfg, invalidCert, t.Artifact, t.RegistryID)
+	if err != nil {
+		res.Status, res.Error = "error", errorWithBody(err, body)
+		return res, ""
+	}
+	res.JobID, res.Status = job.ID, job.Status
+	artifact := job.ArtifactName
+	if artifact == "" {
+		artifact = t.Artifact
+	}
+	if !opts.Wait {
+		return res, ""
+	}
+
+	job, err = waitForJob(ctx, cfg, invalidCert, job, opts.PollInterval, nil)
+	res.Status = job.Status
+	if err != nil {
+		res.Error = err.Error()
+		return res, ""
+	}
+	if IsFailedStatus(job.Status) {
+		return res, ""
+	}
+	if job.ArtifactName != "" {
+		artifact = job.ArtifactName
+	}
+	return res, artifact
+}
+
+// errorWithBody appends a (shortened) API response body to err.
+func errorWithBody(err error, body string) string {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return err.Error()
+	}
+	if len(body) > 200 {
+		body = body[:200] + "..."
+	}
+	return fmt.Sprintf("%v: %s", err, body)
+}
+
+// SummarizeBulkScan counts results per status ("error" for failed submissions), sorted by status.
+func SummarizeBulkScan(results []model.BulkScanResult) (failed int, summary string) {
+	counts := map[string]int{}
+	for _, r := range results {
+		status := r.Status
+		if status == "" {
+			status = "unknown"
+		}
+		if r.Error != "" || IsFailedStatus(r.Status) {
+			failed++
+		}
+		counts[status]++
+	}
+	var keys []string
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+	}
+	return failed, fmt.Sprintf("%d artifacts: %s", len(results), strings.Join(parts, ", "))
+}
@@ -1,16 +1,18 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/arturscheiner/kcskit/internal/model"
-	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
 )
 
-func ListCicd(cfg model.Config, invalidCert bool, page, limit, sort, by, buildNumber, buildPipeline string) (*model.CiCdScansListResponse, string, string, error) {
-	client, err := cfgsvc.NewClient(cfg.Endpoint, cfg.Token, invalidCert, cfg.CaCert)
+func ListCicd(ctx context.Context, cfg model.Config, invalidCert bool, page, limit, sort, by, buildNumber, buildPipeline string) (*model.CiCdScansListResponse, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -28,7 +30,7 @@ func ListCicd(cfg model.Config, invalidCert bool, page, limit, sort, by, buildNu
 	}
 
 	endpoint := "/v1/scans/ci-cd"
-	status, body, err := client.Do("GET", endpoint, params.Encode(), nil)
+	status, body, err := client.Do(ctx, "GET", endpoint, params.Encode(), nil)
 	if err != nil {
 		return nil, string(body), endpoint, err
 	}
@@ -41,4 +43,148 @@ func ListCicd(cfg model.Config, invalidCert bool, page, limit, sort, by, buildNu
 		return nil, string(body), endpoint, fmt.Errorf("failed to parse clusters JSON: %w", err)
 	}
 	return &cr, string(body), endpoint, nil
-}
\ No newline at end of file
+}
+
+// ListAllCicd walks every page of /v1/scans/ci-cd with limit items per page and
+// returns the merged response, a merged JSON body and the endpoint.
+func ListAllCicd(ctx context.Context, cfg model.Config, invalidCert bool, limit int, sort, by, buildNumber, buildPipeline string) (*model.CiCdScansListResponse, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	params := url.Values{}
+	params.Add("sort", sort)
+	params.Add("by", by)
+	if buildNumber != "" {
+		params.Add("build-number", buildNumber)
+	}
+	if buildPipeline != "" {
+		params.Add("build-pipeline", buildPipeline)
+	}
+
+	endpoint := "/v1/scans/ci-cd"
+	items, total, body, err := listAll(ctx, client, endpoint, params, limit, "ci/cd scans", func(r *model.CiCdScansListResponse) ([]model.CiCdScan, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+
+	cr := &model.CiCdScansListResponse{Items: items, Page: 1, Total: total}
+	merged, err := json.Marshal(cr)
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return cr, string(merged), endpoint, nil
+}
+
+// GetCicdScan fetches a single CI/CD scan by ID.
+func GetCicdScan(ctx context.Context, cfg model.Config, invalidCert bool, id string) (*model.CiCdScanDetail, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/scans/ci-cd/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "GET", endpoint, "", nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	var d model.CiCdScanDetail
+	if err := json.Unmarshal(body, &d); err != nil {
+		return nil, string(body), endpoint, fmt.Errorf("failed to parse ci/cd scan JSON: %w", err)
+	}
+	return &d, string(body), endpoint, nil
+}
+
+// FindCicdScan returns the most recent CI/CD scan matching the build filters and,
+// when artifact is set, whose artifact name equals or starts with artifact.
+// Scans created before since (when not zero) are ignored. It returns nil
+// without error when no scan matches (yet).
+func FindCicdScan(ctx context.Context, cfg model.Config, invalidCert bool, buildNumber, buildPipeline, artifact string, since time.Time) (*model.CiCdScan, error) {
+	limit := "1"
+	if artifact != "" {
+		limit = "100"
+	}
+	res, body, _, err := ListCicd(ctx, cfg, invalidCert, "1", limit, "createdAt", "desc", buildNumber, buildPipeline)
+	if err != nil {
+		if body != "" {
+			return nil, fmt.Errorf("%w (response body: %s)", err, body)
+		}
+		return nil, err
+	}
+	for _, s := range res.Items {
+		if !since.IsZero() && s.CreatedAt.Before(since) {
+			// newest first: the rest are older still
+			break
+		}
+		if artifact == "" || s.ArtifactName == artifact || strings.HasPrefix(s.ArtifactName, artifact+":") || strings.HasPrefix(s.ArtifactName, artifact+"@") {
+			found := s
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// EvaluateGate checks a finished CI/CD scan against a risk threshold (the scan
+// fails when its rating is above it or unknown) and a list of risk categories
+// that must not have findings.
+func EvaluateGate(scan model.CiCdScanDetail, threshold string, failOn []string) model.GateResult {
+	res := model.GateResult{
+		ScanID:     scan.ID,
+		Artifact:   scan.ArtifactName,
+		Status:     scan.Status,
+		RiskRating: scan.RiskRating,
+		Threshold:  threshold,
+		Categories: scan.RiskSummary.Counts(),
+		Reasons:    []string{},
+	}
+
+	if IsFailedStatus(scan.Status) {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("scan finished with status %s", scan.Status))
+	}
+	limit, _ := RiskRank(threshold)
+	// an unknown rating fails at every threshold: it cannot be compared
+	if rank, known := RiskRank(scan.RiskRating); !known {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("unknown risk rating %q", scan.RiskRating))
+	} else if rank > limit {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("risk rating %s is above threshold %s", scan.RiskRating, threshold))
+	}
+	for _, c := range failOn {
+		if n := res.Categories[c]; n > 0 {
+			res.Reasons = append(res.Reasons, fmt.Sprintf("%d %s finding(s) present", n, c))
+		}
+	}
+	res.Passed = len(res.Reasons) == 0
+	return res
+}
+
+// CicdImageDetail returns the findings of a CI/CD scan as an image scan result,
+// so the image helpers (findings table, severity summary, SARIF) apply to it.
+func CicdImageDetail(scan model.CiCdScanDetail) model.ImageDetail {
+	return model.ImageDetail{
+		ImageItem:         model.ImageItem{ID: scan.ID, Name: scan.ArtifactName, RiskRating: scan.RiskRating},
+		Digest:            scan.Digest,
+		ScannedAt:         scan.UpdatedAt,
+		Vulnerabilities:   scan.Vulnerabilities,
+		Malware:           scan.Malware,
+		SensitiveData:     scan.SensitiveData,
+		Misconfigurations: scan.Misconfigurations,
+	}
+}
+
+// TriggeredPolicies returns the policy decisions whose result is not "passed".
+func TriggeredPolicies(scan model.CiCdScanDetail) []model.PolicyDecision {
+	var out []model.PolicyDecision
+	for _, d := range scan.PolicyDecisions {
+		if !strings.EqualFold(d.Result, "passed") {
+			out = append(out, d)
+		}
+	}
+	return out
+}
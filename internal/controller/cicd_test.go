@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+func TestEvaluateGate(t *testing.T) {
+	scan := func(status, risk string, malware int) model.CiCdScanDetail {
+		return model.CiCdScanDetail{
+			CiCdScan:    model.CiCdScan{ID: "s1", Status: status, RiskRating: risk},
+			RiskSummary: model.CiCdRiskSummary{Malware: malware},
+		}
+	}
+	tests := []struct {
+		name      string
+		scan      model.CiCdScanDetail
+		threshold string
+		failOn    []string
+		passed    bool
+		reason    string
+	}{
+		{"below threshold", scan("completed", "low", 0), "medium", nil, true, ""},
+		{"at threshold", scan("completed", "Medium", 0), "medium", nil, true, ""},
+		{"above threshold", scan("completed", "high", 0), "medium", nil, false, "risk rating high is above threshold medium"},
+		{"no risk", scan("completed", "", 0), "negligible", nil, true, ""},
+		{"unknown rating", scan("completed", "severe", 0), "medium", nil, false, `unknown risk rating "severe"`},
+		{"unknown rating at critical", scan("completed", "severe", 0), "critical", nil, false, `unknown risk rating "severe"`},
+		{"failed scan", scan("failed", "low", 0), "critical", nil, false, "scan finished with status failed"},
+		{"fail-on category", scan("completed", "low", 2), "critical", []string{"malware"}, false, "2 malware finding(s) present"},
+		{"fail-on without findings", scan("completed", "low", 0), "critical", []string{"malware", "sensitive-data"}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluateGate(tt.scan, tt.threshold, tt.failOn)
+			if got.Passed != tt.passed {
+				t.Fatalf("EvaluateGate() passed = %v, want %v (reasons: %q)", got.Passed, tt.passed, got.Reasons)
+			}
+			if tt.reason == "" {
+				if len(got.Reasons) != 0 {
+					t.Errorf("EvaluateGate() reasons = %q, want none", got.Reasons)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(got.Reasons, "; "), tt.reason) {
+				t.Errorf("EvaluateGate() reasons = %q, want %q", got.Reasons, tt.reason)
+			}
+		})
+	}
+}
@@ -1,22 +1,25 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/arturscheiner/kcskit/internal/model"
-	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
 )
 
 // ListClusters calls /v1/clusters with provided rawQuery and returns parsed items, raw body and error.
-func ListClusters(cfg model.Config, invalidCert bool, rawQuery string) ([]model.ClusterItem, string, string, error) {
-	client, err := cfgsvc.NewClient(cfg.Endpoint, cfg.Token, invalidCert, cfg.CaCert)
+func ListClusters(ctx context.Context, cfg model.Config, invalidCert bool, rawQuery string) ([]model.ClusterItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
 	if err != nil {
 		return nil, "", "", err
 	}
 
 	endpoint := "/v1/clusters"
-	status, body, err := client.Do("GET", endpoint, rawQuery, nil)
+	status, body, err := client.Do(ctx, "GET", endpoint, rawQuery, nil)
 	if err != nil {
 		return nil, string(body), endpoint, err
 	}
@@ -30,3 +33,146 @@ func ListClusters(cfg model.Config, invalidCert bool, rawQuery string) ([]model.
 	}
 	return cr.Items, string(body), endpoint, nil
 }
+
+// ListAllClusters walks every page of /v1/clusters with limit items per page and
+// returns the merged items, a merged JSON body and the endpoint. The "page" and
+// "limit" values of query are overwritten while paging.
+func ListAllClusters(ctx context.Context, cfg model.Config, invalidCert bool, query url.Values, limit int) ([]model.ClusterItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/clusters"
+	items, total, body, err := listAll(ctx, client, endpoint, query, limit, "clusters", func(r *model.ClusterResponse) ([]model.ClusterItem, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+
+	merged, err := json.Marshal(model.ClusterResponse{Total: total, Page: 1, Items: items})
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return items, string(merged), endpoint, nil
+}
+
+// clusterPageSize is the page size used to fetch every namespace or workload of a cluster.
+const clusterPageSize = 100
+
+// FindCluster resolves a cluster ID or (case-insensitive) cluster name.
+func FindCluster(ctx context.Context, cfg model.Config, invalidCert bool, ref string) (*model.ClusterItem, string, error) {
+	items, body, _, err := ListAllClusters(ctx, cfg, invalidCert, url.Values{}, clusterPageSize)
+	if err != nil {
+		return nil, body, err
+	}
+	for i := range items {
+		if items[i].ID == ref {
+			return &items[i], "", nil
+		}
+	}
+	var found []model.ClusterItem
+	for _, it := range items {
+		if strings.EqualFold(it.ClusterName, ref) {
+			found = append(found, it)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, "", fmt.Errorf("cluster %q not found", ref)
+	case 1:
+		return &found[0], "", nil
+	}
+	return nil, "", fmt.Errorf("cluster name %q is ambiguous (%d clusters), use the ID", ref, len(found))
+}
+
+// GetCluster fetches a cluster by ID (GET /v1/clusters/{id}).
+func GetCluster(ctx context.Context, cfg model.Config, invalidCert bool, id string) (*model.ClusterDetail, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/clusters/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "GET", endpoint, "", nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	var detail model.ClusterDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
+		return nil, string(body), endpoint, fmt.Errorf("failed to parse cluster JSON: %w", err)
+	}
+	return &detail, string(body), endpoint, nil
+}
+
+// ListClusterNamespaces returns every namespace of a cluster
+// (GET /v1/clusters/{id}/namespaces), a merged JSON body and the endpoint.
+func ListClusterNamespaces(ctx context.Context, cfg model.Config, invalidCert bool, id string) ([]model.NamespaceItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/clusters/" + url.PathEscape(id) + "/namespaces"
+	items, total, body, err := listAll(ctx, client, endpoint, url.Values{}, clusterPageSize, "namespaces", func(r *model.NamespacesResponse) ([]model.NamespaceItem, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+
+	merged, err := json.Marshal(model.NamespacesResponse{Total: total, Page: 1, Items: items})
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return items, string(merged), endpoint, nil
+}
+
+// ListClusterWorkloads returns every workload of a cluster, optionally limited
+// to one namespace (GET /v1/clusters/{id}/workloads), sorted by risk (highest
+// first), together with a merged JSON body and the endpoint.
+func ListClusterWorkloads(ctx context.Context, cfg model.Config, invalidCert bool, id, namespace string) ([]model.WorkloadItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/clusters/" + url.PathEscape(id) + "/workloads"
+	query := url.Values{}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	items, total, body, err := listAll(ctx, client, endpoint, query, clusterPageSize, "workloads", func(r *model.WorkloadsResponse) ([]model.WorkloadItem, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	sortWorkloadsByRisk(items)
+
+	merged, err := json.Marshal(model.WorkloadsResponse{Total: total, Page: 1, Items: items})
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return items, string(merged), endpoint, nil
+}
+
+// sortWorkloadsByRisk orders workloads by risk rating (highest first), then by namespace and name.
+func sortWorkloadsByRisk(items []model.WorkloadItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ri, _ := RiskRank(items[i].RiskRating)
+		rj, _ := RiskRank(items[j].RiskRating)
+		if ri != rj {
+			return ri > rj
+		}
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -9,26 +10,83 @@ import (
 	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
 )
 
-// SaveConfig saves token/endpoint/ca_cert (merging with existing).
-func SaveConfig(token, endpoint, caCert, aiOllamaEndpoint, aiOllamaModel string) error {
-	toSave := model.Config{
-		Token:            token,
-		Endpoint:         endpoint,
-		CaCert:           caCert,
-		AiOllamaEndpoint: aiOllamaEndpoint,
-		AiOllamaModel:    aiOllamaModel,
-	}
-	return cfgsvc.Save(toSave)
+// SaveConfig saves the non-empty fields of toSave into the named context
+// (merging with existing). An empty name selects the current context.
+func SaveConfig(contextName string, toSave model.Config) error {
+	return cfgsvc.SaveContext(contextName, toSave)
 }
 
-// LoadConfig loads the config file.
+// LoadConfig loads the current context from the config file.
 func LoadConfig() (model.Config, error) {
 	return cfgsvc.Load()
 }
 
-// ValidateConfig ensures token and endpoint are present and endpoint looks valid.
+// LoadConfigContext loads the named context; an empty name selects the current context.
+func LoadConfigContext(name string) (model.Config, error) {
+	return cfgsvc.LoadContext(name)
+}
+
+// ResolveConfig returns the effective configuration for the named context with
+// KCSKIT_* environment overrides applied, and the source of every set value.
+func ResolveConfig(name string) (model.Config, map[string]string, error) {
+	return cfgsvc.Resolve(name)
+}
+
+// ResolveConfigSecrets reads the token and AI API keys kept in secret backends
+// into cfg and records their sources.
+func ResolveConfigSecrets(cfg *model.Config, sources map[string]string) error {
+	return cfgsvc.ResolveSecrets(cfg, sources)
+}
+
+// DescribeConfig lists the settings of cfg with the sources returned by ResolveConfig.
+func DescribeConfig(cfg model.Config, sources map[string]string) []model.ConfigValue {
+	return cfgsvc.Describe(cfg, sources)
+}
+
+// SetConfigPath overrides the config file location; empty restores the default.
+func SetConfigPath(p string) {
+	cfgsvc.SetPath(p)
+}
+
+// ConfigPath returns the config file location in use.
+func ConfigPath() (string, error) {
+	return cfgsvc.Path()
+}
+
+// ValidSecretBackend reports whether name is a known token secret backend.
+func ValidSecretBackend(name string) bool {
+	return cfgsvc.ValidSecretBackend(name) && name != cfgsvc.SecretBackendExec
+}
+
+// ListContexts returns all configured contexts and the name of the current one.
+func ListContexts() ([]model.NamedContext, string, error) {
+	f, err := cfgsvc.LoadFile()
+	if err != nil {
+		return nil, "", err
+	}
+	return f.Contexts, f.CurrentContext, nil
+}
+
+// UseContext makes name the current context.
+func UseContext(name string) error {
+	return cfgsvc.UseContext(name)
+}
+
+// RenameContext renames a context.
+func RenameContext(oldName, newName string) error {
+	return cfgsvc.RenameContext(oldName, newName)
+}
+
+// DeleteContext removes a context and its stored secrets; secrets that could
+// not be removed are returned as warnings.
+func DeleteContext(name string) ([]error, error) {
+	return cfgsvc.DeleteContext(name)
+}
+
+// ValidateConfig ensures token (or a reference to it) and endpoint are present
+// and endpoint looks valid. A referenced token is read when the client is created.
 func ValidateConfig(cfg model.Config) error {
-	if strings.TrimSpace(cfg.Token) == "" {
+	if strings.TrimSpace(cfg.Token) == "" && cfg.TokenRef == "" {
 		return errors.New("token is empty")
 	}
 	if strings.TrimSpace(cfg.Endpoint) == "" {
@@ -39,13 +97,13 @@ func ValidateConfig(cfg model.Config) error {
 
 // TestConfigConnection creates a reusable API client and performs the health action,
 // returning the raw response body (JSON) and any error. Caller can parse the JSON.
-func TestConfigConnection(cfg model.Config, invalidCert bool) (string, error) {
-	client, err := cfgsvc.NewClient(cfg.Endpoint, cfg.Token, invalidCert, cfg.CaCert)
+func TestConfigConnection(ctx context.Context, cfg model.Config, invalidCert bool) (string, error) {
+	client, err := newClient(cfg, invalidCert)
 	if err != nil {
 		return "", err
 	}
 
-	status, body, err := client.Do("GET", "/v1/core-health", "", nil)
+	status, body, err := client.Do(ctx, "GET", "/v1/core-health", "", nil)
 	if err != nil {
 		return string(body), err
 	}
@@ -54,3 +112,25 @@ func TestConfigConnection(cfg model.Config, invalidCert bool) (string, error) {
 	}
 	return string(body), nil
 }
+
+// newClient creates an API client for cfg, applying the configured timeout and retry policy.
+func newClient(cfg model.Config, invalidCert bool) (*cfgsvc.APIClient, error) {
+	token, err := cfgsvc.ResolveToken(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfgsvc.NewClient(cfg.Endpoint, token, invalidCert, cfg.CaCert)
+	if err != nil {
+		return nil, err
+	}
+	client.SetTimeout(cfg.HTTPTimeout)
+	client.Retry = cfgsvc.RetryPolicy{
+		MaxAttempts:    cfg.RetryMaxAttempts,
+		InitialBackoff: cfg.RetryBackoff,
+		MaxBackoff:     cfg.RetryMaxBackoff,
+	}
+	if cfg.RetryNonIdempotent != nil {
+		client.Retry.RetryNonIdempotent = *cfg.RetryNonIdempotent
+	}
+	return client, nil
+}
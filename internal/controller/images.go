@@ -1,23 +1,26 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/arturscheiner/kcskit/internal/model"
-	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
 )
 
 // ListImages calls the /v1/images/registry endpoint with the provided rawQuery (URL-encoded).
 // Returns parsed items, raw response body and any error.
-func ListImages(cfg model.Config, invalidCert bool, rawQuery string) ([]model.ImageItem, string, string, error) {
-	client, err := cfgsvc.NewClient(cfg.Endpoint, cfg.Token, invalidCert, cfg.CaCert)
+func ListImages(ctx context.Context, cfg model.Config, invalidCert bool, rawQuery string) ([]model.ImageItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
 	if err != nil {
 		return nil, "", "", err
 	}
 
 	endpoint := "/v1/images/registry"
-	status, body, err := client.Do("GET", endpoint, rawQuery, nil)
+	status, body, err := client.Do(ctx, "GET", endpoint, rawQuery, nil)
 	if err != nil {
 		return nil, string(body), endpoint, err
 	}
@@ -31,3 +34,187 @@ func ListImages(cfg model.Config, invalidCert bool, rawQuery string) ([]model.Im
 	}
 	return ir.Items, string(body), endpoint, nil
 }
+
+// ListAllImages walks every page of /v1/images/registry with limit items per page and
+// returns the merged items, a merged JSON body and the endpoint. The "page" and
+// "limit" values of query are overwritten while paging.
+func ListAllImages(ctx context.Context, cfg model.Config, invalidCert bool, query url.Values, limit int) ([]model.ImageItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/images/registry"
+	items, total, body, err := listAll(ctx, client, endpoint, query, limit, "images", func(r *model.ImagesResponse) ([]model.ImageItem, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+
+	merged, err := json.Marshal(model.ImagesResponse{Total: total, Page: 1, Items: items})
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return items, string(merged), endpoint, nil
+}
+
+// Severities lists the finding severities accepted by --severity, from least to most severe.
+var Severities = []string{"unknown", "negligible", "low", "medium", "high", "critical"}
+
+// GetImage fetches the full scan result of an image (GET /v1/images/registry/{id}).
+// Returns the parsed detail, raw response body, endpoint and error.
+func GetImage(ctx context.Context, cfg model.Config, invalidCert bool, id string) (*model.ImageDetail, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/images/registry/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "GET", endpoint, "", nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	var detail model.ImageDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
+		return nil, string(body), endpoint, fmt.Errorf("failed to parse image JSON: %w", err)
+	}
+	return &detail, string(body), endpoint, nil
+}
+
+// ResolveImageID returns the ID of the image named ref (name:tag, matched
+// exactly); any other ref is taken to be an image ID. When the first segment
+// of ref is the host, name or ID of a registry, only that registry's images
+// are searched for the rest of ref; otherwise all images are searched.
+func ResolveImageID(ctx context.Context, cfg model.Config, invalidCert bool, ref string) (string, string, error) {
+	if !strings.ContainsAny(ref, ":/@") {
+		return ref, "", nil
+	}
+	if first, rest, ok := strings.Cut(ref, "/"); ok {
+		registries, body, _, err := ListRegistries(ctx, cfg, invalidCert)
+		if err != nil {
+			return "", body, err
+		}
+		if reg, found := NewRegistryResolver(registries).registry(first); found {
+			image, body, err := FindImageResult(ctx, cfg, invalidCert, rest, reg.ID)
+			if err != nil {
+				return "", body, err
+			}
+			if image != nil {
+				return image.ID, "", nil
+			}
+		}
+	}
+	image, body, err := FindImageResult(ctx, cfg, invalidCert, ref, "")
+	if err != nil {
+		return "", body, err
+	}
+	if image == nil {
+		return "", "", fmt.Errorf("image %q not found", ref)
+	}
+	return image.ID, "", nil
+}
+
+// FilterImageDetail keeps the findings whose severity is one of severities
+// (all when empty). With fixableOnly only vulnerabilities that have a fixed
+// version are kept, and the other categories are dropped.
+func FilterImageDetail(detail model.ImageDetail, severities []string, fixableOnly bool) model.ImageDetail {
+	want := map[string]bool{}
+	for _, s := range severities {
+		want[normalizeSeverity(s)] = true
+	}
+	keep := func(severity string) bool {
+		return len(want) == 0 || want[normalizeSeverity(severity)]
+	}
+
+	out := detail
+	out.Vulnerabilities = nil
+	for _, v := range detail.Vulnerabilities {
+		if keep(v.Severity) && (!fixableOnly || v.FixedVersion != "") {
+			out.Vulnerabilities = append(out.Vulnerabilities, v)
+		}
+	}
+	out.Malware, out.SensitiveData, out.Misconfigurations = nil, nil, nil
+	if fixableOnly {
+		return out
+	}
+	for _, m := range detail.Malware {
+		if keep(m.Severity) {
+			out.Malware = append(out.Malware, m)
+		}
+	}
+	for _, s := range detail.SensitiveData {
+		if keep(s.Severity) {
+			out.SensitiveData = append(out.SensitiveData, s)
+		}
+	}
+	for _, m := range detail.Misconfigurations {
+		if keep(m.Severity) {
+			out.Misconfigurations = append(out.Misconfigurations, m)
+		}
+	}
+	return out
+}
+
+// normalizeSeverity lower-cases a severity; empty severities are "unknown".
+func normalizeSeverity(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// ImageFindings flattens the findings of detail, in category order
+// (vulnerabilities, malware, sensitive-data, misconfiguration).
+func ImageFindings(detail model.ImageDetail) []model.ImageFinding {
+	var out []model.ImageFinding
+	for _, v := range detail.Vulnerabilities {
+		out = append(out, model.ImageFinding{Category: "vulnerabilities", ID: v.ID, Severity: normalizeSeverity(v.Severity), Package: v.PackageName, Installed: v.InstalledVersion, Fixed: v.FixedVersion, Title: v.Title})
+	}
+	for _, m := range detail.Malware {
+		out = append(out, model.ImageFinding{Category: "malware", ID: m.Name, Severity: normalizeSeverity(m.Severity), Path: m.Path})
+	}
+	for _, s := range detail.SensitiveData {
+		out = append(out, model.ImageFinding{Category: "sensitive-data", ID: s.Type, Severity: normalizeSeverity(s.Severity), Path: s.Path, Title: s.Title})
+	}
+	for _, m := range detail.Misconfigurations {
+		out = append(out, model.ImageFinding{Category: "misconfiguration", ID: m.ID, Severity: normalizeSeverity(m.Severity), Path: m.Path, Title: m.Title})
+	}
+	return out
+}
+
+// SeveritySummary counts findings per severity, most severe first ("2 critical, 5 high").
+func SeveritySummary(findings []model.ImageFinding) string {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for i := len(Severities) - 1; i >= 0; i-- {
+		if n := counts[Severities[i]]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, Severities[i]))
+			delete(counts, Severities[i])
+		}
+	}
+	for _, s := range sortedCountKeys(counts) {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedCountKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
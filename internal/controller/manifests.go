@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// podSpecPaths locates the pod spec inside each supported Kubernetes workload kind.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"PodTemplate":           {"template", "spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// ManifestImages returns every unique image referenced by the Kubernetes
+// workloads and docker-compose services in path, in order of appearance.
+// path is a YAML file, a directory (searched recursively for *.yaml and *.yml)
+// or "-" for stdin (e.g. the output of helm template). Files in a directory
+// that are not valid YAML (such as unrendered Helm templates) are skipped and
+// returned as warnings.
+func ManifestImages(path string) ([]string, []error, error) {
+	seen := map[string]bool{}
+	var images []string
+	var warnings []error
+	add := func(found []string) {
+		for _, img := range found {
+			if !seen[img] {
+				seen[img] = true
+				images = append(images, img)
+			}
+		}
+	}
+
+	if path == "-" {
+		found, err := ParseManifestImages(os.Stdin)
+		if err != nil {
+			return nil, nil, fmt.Errorf("stdin: %w", err)
+		}
+		add(found)
+		return images, nil, nil
+	}
+
+	files, err := yamlFiles(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range files {
+		found, err := parseManifestFile(p)
+		if err != nil {
+			if p == path {
+				return nil, nil, fmt.Errorf("%s: %w", p, err)
+			}
+			warnings = append(warnings, fmt.Errorf("skipping %s: %w", p, err))
+			continue
+		}
+		add(found)
+	}
+	return images, warnings, nil
+}
+
+func parseManifestFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseManifestImages(f)
+}
+
+// yamlFiles returns path itself when it is a file, or the *.yaml and *.yml
+// files below the directory path (skipping hidden directories) in lexical order.
+func yamlFiles(path string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p != path {
+			if ext := strings.ToLower(filepath.Ext(p)); ext != ".yaml" && ext != ".yml" {
+				return nil
+			}
+		}
+		files = append(files, p)
+		return nil
+	})
+	return files, err
+}
+
+// ParseManifestImages extracts the image references from a stream of YAML
+// documents: Kubernetes workloads (containers, initContainers and
+// ephemeralContainers, including those in List items) and docker-compose files.
+func ParseManifestImages(r io.Reader) ([]string, error) {
+	var images []string
+	dec := yaml.NewDecoder(r)
+	for {
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return images, nil
+			}
+			return nil, err
+		}
+		// empty documents and non-mapping documents hold no workloads
+		if m, ok := doc.(map[string]any); ok {
+			images = append(images, documentImages(m)...)
+		}
+	}
+}
+
+// documentImages returns the images of a single decoded YAML document.
+func documentImages(doc map[string]any) []string {
+	if doc == nil {
+		return nil
+	}
+	kind, _ := doc["kind"].(string)
+	if kind == "" {
+		// docker-compose: services.<name>.image
+		services, _ := doc["services"].(map[string]any)
+		var images []string
+		for _, name := range sortedAnyKeys(services) {
+			svc, _ := services[name].(map[string]any)
+			if img, ok := svc["image"].(string); ok && img != "" {
+				images = append(images, img)
+			}
+		}
+		return images
+	}
+
+	if strings.HasSuffix(kind, "List") {
+		var images []string
+		items, _ := doc["items"].([]any)
+		for _, it := range items {
+			if m, ok := it.(map[string]any); ok {
+				images = append(images, documentImages(m)...)
+			}
+		}
+		return images
+	}
+
+	path, ok := podSpecPaths[kind]
+	if !ok {
+		return nil
+	}
+	spec := doc
+	for _, key := range path {
+		spec, _ = spec[key].(map[string]any)
+		if spec == nil {
+			return nil
+		}
+	}
+	var images []string
+	for _, key := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		containers, _ := spec[key].([]any)
+		for _, c := range containers {
+			m, _ := c.(map[string]any)
+			if img, ok := m["image"].(string); ok && img != "" {
+				images = append(images, img)
+			}
+		}
+	}
+	return images
+}
+
+func sortedAnyKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseManifestImages(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"deployment", `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      initContainers:
+        - image: busybox:1.36
+      containers:
+        - name: web
+          image: nginx:1.25
+        - name: sidecar
+          image: envoyproxy/envoy:v1.30
+`, []string{"busybox:1.36", "nginx:1.25", "envoyproxy/envoy:v1.30"}},
+		{"pod with ephemeral container", `
+kind: Pod
+spec:
+  containers: [{image: app:1}]
+  ephemeralContainers: [{image: debug:1}]
+`, []string{"app:1", "debug:1"}},
+		{"cronjob", `
+kind: CronJob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers: [{image: registry.local/backup:2}]
+`, []string{"registry.local/backup:2"}},
+		{"list", `
+kind: List
+items:
+  - kind: Deployment
+    spec: {template: {spec: {containers: [{image: a:1}]}}}
+  - kind: Service
+    spec: {ports: [{port: 80}]}
+  - kind: StatefulSet
+    spec: {template: {spec: {containers: [{image: b:1}]}}}
+`, []string{"a:1", "b:1"}},
+		{"compose", `
+services:
+  web:
+    image: nginx:1.25
+  db:
+    image: postgres:16
+  app:
+    build: .
+`, []string{"postgres:16", "nginx:1.25"}},
+		{"multiple documents", `
+kind: Job
+spec: {template: {spec: {containers: [{image: migrate:3}]}}}
+---
+---
+kind: ConfigMap
+data: {image: not-an-image}
+---
+- just
+- a list
+---
+kind: DaemonSet
+spec: {template: {spec: {containers: [{image: agent:1}, {name: no-image}]}}}
+`, []string{"migrate:3", "agent:1"}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseManifestImages(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatalf("ParseManifestImages: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseManifestImages() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseManifestImages(strings.NewReader("kind: Pod\n  spec: [")); err == nil {
+		t.Error("ParseManifestImages with invalid YAML succeeded")
+	}
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
+)
+
+// pageQuery returns a copy of query with the page and limit parameters set, encoded.
+func pageQuery(query url.Values, page, limit int) string {
+	v := url.Values{}
+	for k, vals := range query {
+		v[k] = append([]string(nil), vals...)
+	}
+	v.Set("page", strconv.Itoa(page))
+	v.Set("limit", strconv.Itoa(limit))
+	return v.Encode()
+}
+
+// listAll walks every page of a list endpoint with limit items per page. Each
+// page is parsed into a response R and page returns its items and total; what
+// names the items in parse errors. Returns the items, the total and, on error,
+// the body of the failed page.
+func listAll[T, R any](ctx context.Context, client *cfgsvc.APIClient, endpoint string, query url.Values, limit int, what string, page func(r *R) ([]T, int)) ([]T, int, []byte, error) {
+	return cfgsvc.FetchAll(func(p int) ([]T, int, []byte, error) {
+		status, body, err := client.Do(ctx, "GET", endpoint, pageQuery(query, p, limit), nil)
+		if err != nil {
+			return nil, 0, body, err
+		}
+		if status < 200 || status >= 300 {
+			return nil, 0, body, fmt.Errorf("received HTTP %d", status)
+		}
+		var r R
+		if err := json.Unmarshal(body, &r); err != nil {
+			return nil, 0, body, fmt.Errorf("failed to parse %s JSON: %w", what, err)
+		}
+		items, total := page(&r)
+		return items, total, body, nil
+	})
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
+)
+
+// pagedServer serves n images in pages, reporting total (which may differ from n).
+// Each request is recorded in calls.
+func pagedServer(t *testing.T, n, total int, calls *[]url.Values) *cfgsvc.APIClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		*calls = append(*calls, q)
+		page, _ := strconv.Atoi(q.Get("page"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		resp := model.ImagesResponse{Total: total, Page: page, Items: []model.ImageItem{}}
+		for i := (page - 1) * limit; i < page*limit && i < n; i++ {
+			resp.Items = append(resp.Items, model.ImageItem{ID: strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := cfgsvc.NewClient(srv.URL, "t", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestListAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		n, total  int
+		limit     int
+		wantItems int
+		wantTotal int
+		wantCalls int
+	}{
+		{"exact pages", 6, 6, 3, 6, 6, 2},
+		{"short last page", 7, 7, 3, 7, 7, 3},
+		{"empty", 0, 0, 3, 0, 0, 1},
+		{"total too high", 4, 10, 3, 4, 10, 3},
+		{"total too low", 5, 2, 3, 3, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []url.Values
+			c := pagedServer(t, tt.n, tt.total, &calls)
+			query := url.Values{"registry": {"r1"}, "page": {"9"}}
+			items, total, _, err := listAll(context.Background(), c, "/v1/images/registry", query, tt.limit, "images", func(r *model.ImagesResponse) ([]model.ImageItem, int) {
+				return r.Items, r.Total
+			})
+			if err != nil {
+				t.Fatalf("listAll: %v", err)
+			}
+			if len(items) != tt.wantItems || total != tt.wantTotal || len(calls) != tt.wantCalls {
+				t.Errorf("listAll = %d items, total %d, %d calls; want %d, %d, %d", len(items), total, len(calls), tt.wantItems, tt.wantTotal, tt.wantCalls)
+			}
+			for i, q := range calls {
+				if q.Get("page") != strconv.Itoa(i+1) || q.Get("limit") != strconv.Itoa(tt.limit) || q.Get("registry") != "r1" {
+					t.Errorf("call %d query = %v", i+1, q)
+				}
+			}
+			if query.Get("page") != "9" {
+				t.Errorf("listAll modified the query: %v", query)
+			}
+		})
+	}
+}
+
+func TestListAllErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"http error", http.StatusForbidden, `{"message":"no"}`, "received HTTP 403"},
+		{"bad json", http.StatusOK, `{"items":`, "failed to parse images JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			c, err := cfgsvc.NewClient(srv.URL, "t", false, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, body, err := listAll(context.Background(), c, "/v1/images/registry", nil, 10, "images", func(r *model.ImagesResponse) ([]model.ImageItem, int) {
+				return r.Items, r.Total
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("listAll error = %v, want %q", err, tt.want)
+			}
+			if string(body) != tt.body {
+				t.Errorf("listAll body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
+)
+
+// Poll calls check immediately and then every interval until it reports done,
+// returns an error, or ctx is done (ctx.Err() is returned, e.g. context.DeadlineExceeded
+// when the global --timeout expires).
+func Poll(ctx context.Context, interval time.Duration, check func(ctx context.Context) (bool, error)) error {
+	return cfgsvc.Poll(ctx, interval, check)
+}
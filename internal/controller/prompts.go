@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
+)
+
+// DefaultPromptName is the prompt template every command falls back to.
+const DefaultPromptName = "default"
+
+// BuiltinPromptSource names the built-in template in PromptTemplate.Sources.
+const BuiltinPromptSource = "built-in"
+
+// BuiltinPromptTemplate is the template AI reports use unless default.tmpl or
+// a command template in the prompts directory redefines its blocks.
+const BuiltinPromptTemplate = `{{/*
+kcskit AI report template (Go text/template). A template only needs to define the
+blocks it changes: the others come from default.tmpl, then from the built-in
+template. Text outside of any block replaces the "prompt" block.
+
+Blocks:
+  system   system prompt
+  prompt   request sent to the model with the command output
+  header   Markdown header printed above the answer
+
+Fields:
+  .Command .Cluster .Risk .ReportTitle .ApiEndpoint   report header of the command
+  .Name .Model .Date                                  template name, model, report date
+  .Items        items of the output (parsed JSON, empty when .Summarised)
+  .Data         the whole output (parsed JSON, nil when .Summarised)
+  .JSON         the output as JSON, trimmed to fit the context window
+  .RawJSON      the output as returned by KCS (not trimmed, empty when .Summarised)
+  .Summarised   the output was too large and was summarised in .Parts parts
+  .Summaries    the merged summaries when .Summarised
+  .InputTokens .Budget                                header only: token usage
+
+Functions: json, upper, lower, join
+*/ -}}
+{{define "system" -}}
+You are an expert on Kaspersky Container Security. You are using a command line utility called kcskit that calls the KCS API.
+{{- end}}
+
+{{define "prompt" -}}
+You have executed the command '{{.Command}}' that calls the kcs api {{.ApiEndpoint}} .
+{{- if .Summarised}} Its output was too large to evaluate at once, so it was summarised in {{.Parts}} parts. Evaluate these summaries and give some insights about this:
+
+{{.Summaries}}
+{{- else}}Evaluate its output and give some insights about this: {{.JSON}}
+{{- end}}
+{{- end}}
+
+{{define "header" -}}
+# {{.ReportTitle}}
+
+**Command line:** ` + "`{{.Command}}`" + `
+
+**Date and Time:** {{.Date}}
+
+**Risk Status Summary:** {{.Risk}}
+
+**Purpose of the Report:** Automated security status and recommendations.
+
+**Model:** {{.Model}}
+
+**Input Tokens:** {{.InputTokens}}
+
+**Token Budget:** {{.Budget}}
+
+---
+
+{{end}}
+`
+
+// validPromptName restricts template names to file names in the prompts directory.
+var validPromptName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// promptFuncs are the functions available to prompt templates.
+var promptFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  func(sep string, v []string) string { return strings.Join(v, sep) },
+}
+
+// PromptData is the data AI report templates are executed with.
+type PromptData struct {
+	model.OllamaHeader
+
+	Name       string
+	Model      string
+	Date       string
+	Items      []any
+	Data       any
+	JSON       string
+	RawJSON    string
+	Summarised bool
+	Parts      int
+	Summaries  string
+
+	InputTokens string
+	Budget      string
+}
+
+// PromptTemplate is the prompt template of a command: the built-in template
+// with the blocks of default.tmpl and of the command template layered on top.
+type PromptTemplate struct {
+	Name    string
+	Sources []string // built-in, then the files that were applied
+
+	set *template.Template
+}
+
+// PromptName returns the template name of a command path, e.g. "images-list"
+// for "kcskit images list".
+func PromptName(commandPath string) string {
+	fields := strings.Fields(commandPath)
+	if len(fields) > 1 {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, "-")
+}
+
+// ValidatePromptName checks that name can be used as a template file name.
+func ValidatePromptName(name string) error {
+	if !validPromptName.MatchString(name) {
+		return fmt.Errorf("invalid prompt name %q (lowercase letters, digits and dashes, e.g. images-list)", name)
+	}
+	return nil
+}
+
+// LoadPromptTemplate returns the template of the named command. override (the
+// --prompt flag) replaces the command template: a file path, or the name of a
+// template in the prompts directory.
+func LoadPromptTemplate(name, override string) (*PromptTemplate, error) {
+	p := &PromptTemplate{Name: name, set: template.New("kcskit").Funcs(promptFuncs)}
+	if err := p.addLayer(BuiltinPromptSource, BuiltinPromptTemplate); err != nil {
+		return nil, err
+	}
+	if err := p.addFile(DefaultPromptName, false); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case override != "" && (strings.ContainsRune(override, os.PathSeparator) || strings.HasSuffix(override, ".tmpl")):
+		b, err := os.ReadFile(override)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.addLayer(override, string(b)); err != nil {
+			return nil, err
+		}
+	case override != "":
+		if err := ValidatePromptName(override); err != nil {
+			return nil, err
+		}
+		if err := p.addFile(override, true); err != nil {
+			return nil, err
+		}
+	case name != "" && name != DefaultPromptName:
+		if err := p.addFile(name, false); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// addFile applies the named template of the prompts directory; a missing file
+// is an error only when required.
+func (p *PromptTemplate) addFile(name string, required bool) error {
+	text, path, err := cfgsvc.ReadPrompt(name)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return p.addLayer(path, text)
+}
+
+// addLayer parses text and (re)defines the blocks it contains. A non-empty
+// body outside of the blocks redefines "prompt".
+func (p *PromptTemplate) addLayer(source, text string) error {
+	lt, err := template.New(source).Funcs(promptFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid prompt template: %w", err)
+	}
+	for _, t := range lt.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		name := t.Name()
+		if name == source {
+			if parse.IsEmptyTree(t.Tree.Root) {
+				continue
+			}
+			name = "prompt"
+		}
+		if _, err := p.set.AddParseTree(name, t.Tree); err != nil {
+			return fmt.Errorf("invalid prompt template %s: %w", source, err)
+		}
+	}
+	p.Sources = append(p.Sources, source)
+	return nil
+}
+
+// Render executes a block ("system", "prompt" or "header") with data.
+func (p *PromptTemplate) Render(block string, data PromptData) (string, error) {
+	var buf bytes.Buffer
+	if err := p.set.ExecuteTemplate(&buf, block, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", p.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// PromptText returns the text of the most specific template file of the named
+// command and its path, or the built-in template.
+func PromptText(name string) (string, string, error) {
+	for _, n := range []string{name, DefaultPromptName} {
+		text, path, err := cfgsvc.ReadPrompt(n)
+		if err == nil {
+			return text, path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+	}
+	return BuiltinPromptTemplate, BuiltinPromptSource, nil
+}
+
+// EnsurePromptFile returns the path of the named template file. A missing
+// file is created with a commented stub only, so the blocks it does not
+// define keep coming from default.tmpl and the built-in template.
+func EnsurePromptFile(name string) (string, error) {
+	if _, path, err := cfgsvc.ReadPrompt(name); err == nil {
+		return path, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	return cfgsvc.WritePrompt(name, promptStub(name))
+}
+
+// promptStub returns the initial text of a new template file: the field
+// reference of the built-in template and a commented example block.
+func promptStub(name string) string {
+	doc, _, _ := strings.Cut(BuiltinPromptTemplate, "*/ -}}")
+	layers := "default.tmpl and the built-in template"
+	if name == DefaultPromptName {
+		layers = "the built-in template"
+	}
+	return doc + "*/ -}}\n" + fmt.Sprintf(`{{/*
+%s.tmpl is layered on top of %s.
+Uncomment and edit a block to change it; the blocks not defined here keep
+their text, which 'kcskit ai prompts show default' prints.
+
+{{define "system" -}}
+You are an expert on Kaspersky Container Security. Answer in German.
+{{- end}}
+*/ -}}
+`, name, layers)
+}
@@ -1,23 +1,32 @@
 package controller
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/arturscheiner/kcskit/internal/model"
-	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
 )
 
 // ListRegistries fetches image registries via the API and returns parsed items,
 // the raw response body and any error. It uses paging/query params suitable for listing.
-func ListRegistries(cfg model.Config, invalidCert bool) ([]model.RegistryItem, string, string, error) {
-	client, err := cfgsvc.NewClient(cfg.Endpoint, cfg.Token, invalidCert, cfg.CaCert)
+func ListRegistries(ctx context.Context, cfg model.Config, invalidCert bool) ([]model.RegistryItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
 	if err != nil {
 		return nil, "", "", err
 	}
 
 	endpoint := "/v1/registries"
-	status, body, err := client.Do("GET", endpoint, "", nil)
+	status, body, err := client.Do(ctx, "GET", endpoint, "", nil)
 	if err != nil {
 		return nil, string(body), endpoint, err
 	}
@@ -31,3 +40,294 @@ func ListRegistries(cfg model.Config, invalidCert bool) ([]model.RegistryItem, s
 	}
 	return items, string(body), endpoint, nil
 }
+
+// RegistryAuthTypes lists the supported authentication types and the
+// RegistryAuth fields (YAML names) each of them requires.
+var RegistryAuthTypes = map[string][]string{
+	"none":            nil,
+	"basic":           {"username", "password"},
+	"token":           {"token"},
+	"aws":             {"accessKeyId", "secretAccessKey", "region"},
+	"service-account": {"serviceAccountKey"},
+}
+
+// AuthTypeNames returns the supported authentication types, sorted.
+func AuthTypeNames() []string {
+	names := make([]string, 0, len(RegistryAuthTypes))
+	for n := range RegistryAuthTypes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateRegistrySpec checks the fields required to create a registry. With
+// credentials false (updates) missing credentials are allowed, so the
+// registry keeps its stored ones.
+func ValidateRegistrySpec(spec model.RegistrySpec, credentials bool) error {
+	if spec.Kind != "" && spec.Kind != "Registry" {
+		return fmt.Errorf("unsupported kind %q (want Registry)", spec.Kind)
+	}
+	var missing []string
+	if spec.RegistryName == "" {
+		missing = append(missing, "name")
+	}
+	if spec.RegistryType == "" {
+		missing = append(missing, "type")
+	}
+	if spec.RegistryUrl == "" {
+		missing = append(missing, "url")
+	}
+	required, ok := RegistryAuthTypes[spec.AuthenticationType]
+	if !ok {
+		return fmt.Errorf("registry %q: unknown authentication type %q (want %s)", spec.RegistryName, spec.AuthenticationType, strings.Join(AuthTypeNames(), "|"))
+	}
+	if credentials {
+		values := authValues(spec.RegistryAuth)
+		for _, f := range required {
+			if values[f] == "" {
+				missing = append(missing, "auth."+f)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("registry %q: missing %s", spec.RegistryName, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// authValues returns the credential fields of auth by YAML name.
+func authValues(auth model.RegistryAuth) map[string]string {
+	return map[string]string{
+		"username":          auth.Username,
+		"password":          auth.Password,
+		"token":             auth.Token,
+		"accessKeyId":       auth.AccessKeyID,
+		"secretAccessKey":   auth.SecretAccessKey,
+		"region":            auth.Region,
+		"serviceAccountKey": auth.ServiceAccountKey,
+	}
+}
+
+// envRef matches ${VAR} references in spec credentials.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} references in s with environment variables. A bare
+// "$" is kept as is (e.g. Harbor robot account names).
+func expandEnv(s string) (string, error) {
+	var missing []string
+	out := envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// ReadRegistrySpecs decodes the kind: Registry documents of a YAML stream
+// (documents without a kind are registries) and expands ${VAR} references in
+// their credentials. Documents of other kinds are returned as an error.
+func ReadRegistrySpecs(r io.Reader) ([]model.RegistrySpec, error) {
+	var set model.ResourceSet
+	if err := decodeResources(r, "Registry", &set); err != nil {
+		return nil, err
+	}
+	return set.Registries, nil
+}
+
+// MergeRegistrySpec decodes the single kind: Registry document of a YAML
+// stream onto spec, so the fields the document leaves out keep their value.
+func MergeRegistrySpec(r io.Reader, spec model.RegistrySpec) (model.RegistrySpec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return spec, err
+	}
+	// checks the kinds and the number of documents
+	specs, err := ReadRegistrySpecs(bytes.NewReader(data))
+	if err != nil {
+		return spec, err
+	}
+	if len(specs) != 1 {
+		return spec, fmt.Errorf("expected one registry, found %d", len(specs))
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
+			return spec, err
+		}
+		if len(node.Content) == 0 {
+			continue
+		}
+		if err := node.Decode(&spec); err != nil {
+			return spec, err
+		}
+		spec.Kind = "Registry"
+		return spec, expandRegistryCredentials(&spec)
+	}
+}
+
+// expandRegistryCredentials expands ${VAR} references in the credentials of spec.
+func expandRegistryCredentials(spec *model.RegistrySpec) error {
+	for _, f := range []*string{&spec.Username, &spec.Password, &spec.Token, &spec.AccessKeyID, &spec.SecretAccessKey, &spec.ServiceAccountKey} {
+		v, err := expandEnv(*f)
+		if err != nil {
+			return fmt.Errorf("registry %q: %w", spec.RegistryName, err)
+		}
+		*f = v
+	}
+	return nil
+}
+
+// RegistrySpecFromItem returns the spec of an existing registry, without credentials.
+func RegistrySpecFromItem(item model.RegistryItem) model.RegistrySpec {
+	return model.RegistrySpec{
+		Kind:         "Registry",
+		RegistryName: item.RegistryName,
+		RegistryType: item.RegistryType,
+		RegistryUrl:  item.RegistryUrl,
+		ApiUrl:       item.ApiUrl,
+		Description:  item.Description,
+		RegistryAuth: model.RegistryAuth{AuthenticationType: item.AuthenticationType},
+	}
+}
+
+// FindRegistry returns the registry whose ID or (case-insensitive) name is ref.
+func FindRegistry(ctx context.Context, cfg model.Config, invalidCert bool, ref string) (*model.RegistryItem, string, error) {
+	items, body, _, err := ListRegistries(ctx, cfg, invalidCert)
+	if err != nil {
+		return nil, body, err
+	}
+	for i := range items {
+		if items[i].ID == ref {
+			return &items[i], "", nil
+		}
+	}
+	var found []model.RegistryItem
+	for _, it := range items {
+		if strings.EqualFold(it.RegistryName, ref) {
+			found = append(found, it)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, "", fmt.Errorf("registry %q not found", ref)
+	case 1:
+		return &found[0], "", nil
+	}
+	return nil, "", fmt.Errorf("registry name %q is ambiguous (%d registries), use the ID", ref, len(found))
+}
+
+// GetRegistry fetches a registry by ID (GET /v1/registries/{id}).
+func GetRegistry(ctx context.Context, cfg model.Config, invalidCert bool, id string) (*model.RegistryItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/registries/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "GET", endpoint, "", nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	var item model.RegistryItem
+	if err := json.Unmarshal(body, &item); err != nil {
+		return nil, string(body), endpoint, fmt.Errorf("failed to parse registry JSON: %w", err)
+	}
+	return &item, string(body), endpoint, nil
+}
+
+// CreateRegistry creates a registry integration (POST /v1/registries).
+func CreateRegistry(ctx context.Context, cfg model.Config, invalidCert bool, spec model.RegistrySpec) (*model.RegistryItem, string, string, error) {
+	return sendRegistry(ctx, cfg, invalidCert, "POST", "/v1/registries", spec)
+}
+
+// UpdateRegistry replaces the settings of registry id (PUT /v1/registries/{id}).
+// Credentials left empty in spec are kept by the server.
+func UpdateRegistry(ctx context.Context, cfg model.Config, invalidCert bool, id string, spec model.RegistrySpec) (*model.RegistryItem, string, string, error) {
+	item, body, endpoint, err := sendRegistry(ctx, cfg, invalidCert, "PUT", "/v1/registries/"+url.PathEscape(id), spec)
+	if item != nil && item.ID == "" {
+		item.ID = id
+	}
+	return item, body, endpoint, err
+}
+
+// sendRegistry sends spec with method to endpoint and parses the returned
+// registry. When the response has no body the registry is built from spec.
+func sendRegistry(ctx context.Context, cfg model.Config, invalidCert bool, method, endpoint string, spec model.RegistrySpec) (*model.RegistryItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	status, body, err := client.SendJSON(ctx, method, endpoint, "", spec, nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	item := model.RegistryItem{
+		RegistryName:       spec.RegistryName,
+		RegistryType:       spec.RegistryType,
+		Description:        spec.Description,
+		RegistryUrl:        spec.RegistryUrl,
+		ApiUrl:             spec.ApiUrl,
+		AuthenticationType: spec.AuthenticationType,
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &item); err != nil {
+			return nil, string(body), endpoint, fmt.Errorf("failed to parse registry JSON: %w", err)
+		}
+	}
+	return &item, string(body), endpoint, nil
+}
+
+// DeleteRegistry deletes registry id (DELETE /v1/registries/{id}).
+func DeleteRegistry(ctx context.Context, cfg model.Config, invalidCert bool, id string) (string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return "", "", err
+	}
+
+	endpoint := "/v1/registries/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "DELETE", endpoint, "", nil)
+	if err != nil {
+		return string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+	return string(body), endpoint, nil
+}
+
+// TestRegistry asks the server to check the connection to registry id
+// (POST /v1/registries/{id}/test) and returns the registry with the updated
+// Status, Message and LastChecked.
+func TestRegistry(ctx context.Context, cfg model.Config, invalidCert bool, id string) (*model.RegistryItem, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/registries/" + url.PathEscape(id) + "/test"
+	status, body, err := client.Do(ctx, "POST", endpoint, "", nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+	return GetRegistry(ctx, cfg, invalidCert, id)
+}
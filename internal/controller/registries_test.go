@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+func TestMergeRegistrySpec(t *testing.T) {
+	live := model.RegistrySpec{
+		Kind:         "Registry",
+		RegistryName: "harbor",
+		RegistryType: "harbor",
+		RegistryUrl:  "https://harbor.local",
+		Description:  "old",
+		RegistryAuth: model.RegistryAuth{AuthenticationType: "basic"},
+	}
+	t.Setenv("HARBOR_PASSWORD", "s3cret")
+	tests := []struct {
+		name string
+		doc  string
+		want func(s *model.RegistrySpec)
+	}{
+		{"description only", "description: new\n", func(s *model.RegistrySpec) { s.Description = "new" }},
+		{"credentials without type", "kind: Registry\nauth:\n  username: bob\n  password: ${HARBOR_PASSWORD}\n", func(s *model.RegistrySpec) {
+			s.Username, s.Password = "bob", "s3cret"
+		}},
+		{"auth type", "---\nauth:\n  type: token\n  token: abc\n", func(s *model.RegistrySpec) {
+			s.AuthenticationType, s.Token = "token", "abc"
+		}},
+		{"empty", "{}\n", func(s *model.RegistrySpec) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeRegistrySpec(strings.NewReader(tt.doc), live)
+			if err != nil {
+				t.Fatalf("MergeRegistrySpec(%q): %v", tt.doc, err)
+			}
+			want := live
+			tt.want(&want)
+			if got != want {
+				t.Errorf("MergeRegistrySpec(%q) = %+v, want %+v", tt.doc, got, want)
+			}
+		})
+	}
+}
+
+func TestMergeRegistrySpecErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"two registries", "name: a\n---\nname: b\n", "expected one registry, found 2"},
+		{"no registry", "", "expected one registry, found 0"},
+		{"other kind", "kind: Cluster\n", "unsupported kind"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MergeRegistrySpec(strings.NewReader(tt.doc), model.RegistrySpec{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("MergeRegistrySpec(%q) error = %v, want %q", tt.doc, err, tt.want)
+			}
+		})
+	}
+}
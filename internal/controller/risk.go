@@ -0,0 +1,70 @@
+package controller
+
+import "strings"
+
+// riskRanks orders KCS risk ratings from harmless to worst.
+var riskRanks = map[string]int{
+	"":           0,
+	"ok":         0,
+	"none":       0,
+	"no risk":    0,
+	"compliant":  0,
+	"negligible": 1,
+	"low":        2,
+	"medium":     3,
+	"high":       4,
+	"critical":   5,
+}
+
+// RiskLevels lists the accepted risk thresholds in increasing order.
+var RiskLevels = []string{"negligible", "low", "medium", "high", "critical"}
+
+// RiskRank returns the position of a risk rating in the KCS ordering
+// (negligible < low < medium < high < critical). Unknown ratings rank as medium
+// and ok is false; EvaluateGate fails them at every threshold so they are never
+// silently treated as safe.
+func RiskRank(risk string) (int, bool) {
+	r, ok := riskRanks[strings.ToLower(strings.TrimSpace(risk))]
+	if !ok {
+		return riskRanks["medium"], false
+	}
+	return r, true
+}
+
+// pendingStatuses are scan/job statuses that mean the work is not finished yet.
+var pendingStatuses = map[string]bool{
+	"new":         true,
+	"created":     true,
+	"pending":     true,
+	"queued":      true,
+	"waiting":     true,
+	"scheduled":   true,
+	"running":     true,
+	"processing":  true,
+	"in_progress": true,
+	"in progress": true,
+	"inprogress":  true,
+	"scanning":    true,
+	"started":     true,
+}
+
+// failedStatuses are terminal statuses that mean the scan did not produce a result.
+var failedStatuses = map[string]bool{
+	"failed":    true,
+	"error":     true,
+	"canceled":  true,
+	"cancelled": true,
+	"aborted":   true,
+	"timeout":   true,
+}
+
+// IsTerminalStatus reports whether a scan or job status is final.
+func IsTerminalStatus(status string) bool {
+	s := strings.ToLower(strings.TrimSpace(status))
+	return s != "" && !pendingStatuses[s]
+}
+
+// IsFailedStatus reports whether a terminal status means the scan failed.
+func IsFailedStatus(status string) bool {
+	return failedStatuses[strings.ToLower(strings.TrimSpace(status))]
+}
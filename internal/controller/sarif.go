@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// RiskCategories are the image risk categories accepted by the risks[] filter (--risks).
+var RiskCategories = []string{"vulnerabilities", "malware", "sensitive-data", "misconfiguration"}
+
+// riskRules describes the SARIF rule emitted for every risk category.
+var riskRules = map[string]model.SarifRule{
+	"vulnerabilities": {
+		ID:               "KCS-VULNERABILITIES",
+		Name:             "ImageVulnerabilities",
+		ShortDescription: model.SarifMessage{Text: "Image contains known vulnerabilities"},
+		FullDescription:  &model.SarifMessage{Text: "Kaspersky Container Security found vulnerable packages in the image."},
+		Properties:       map[string]interface{}{"tags": []string{"security", "container", "vulnerability"}, "security-severity": "7.0"},
+	},
+	"malware": {
+		ID:               "KCS-MALWARE",
+		Name:             "ImageMalware",
+		ShortDescription: model.SarifMessage{Text: "Image contains malware"},
+		FullDescription:  &model.SarifMessage{Text: "Kaspersky Container Security detected malicious objects in the image."},
+		Properties:       map[string]interface{}{"tags": []string{"security", "container", "malware"}, "security-severity": "9.0"},
+	},
+	"sensitive-data": {
+		ID:               "KCS-SENSITIVE-DATA",
+		Name:             "ImageSensitiveData",
+		ShortDescription: model.SarifMessage{Text: "Image contains sensitive data"},
+		FullDescription:  &model.SarifMessage{Text: "Kaspersky Container Security found secrets or other sensitive data in the image."},
+		Properties:       map[string]interface{}{"tags": []string{"security", "container", "secrets"}, "security-severity": "8.0"},
+	},
+	"misconfiguration": {
+		ID:               "KCS-MISCONFIGURATION",
+		Name:             "ImageMisconfiguration",
+		ShortDescription: model.SarifMessage{Text: "Image has misconfigurations"},
+		FullDescription:  &model.SarifMessage{Text: "Kaspersky Container Security found misconfigurations in the image."},
+		Properties:       map[string]interface{}{"tags": []string{"security", "container", "misconfiguration"}, "security-severity": "5.0"},
+	},
+	"cicd": {
+		ID:               "KCS-CICD-RISK",
+		Name:             "CiCdScanRisk",
+		ShortDescription: model.SarifMessage{Text: "CI/CD artifact scan reported a risk"},
+		FullDescription:  &model.SarifMessage{Text: "A Kaspersky Container Security CI/CD scan rated the artifact as risky."},
+		Properties:       map[string]interface{}{"tags": []string{"security", "container", "ci-cd"}},
+	},
+}
+
+// SarifLevel maps a KCS risk rating or severity to a SARIF result level.
+func SarifLevel(risk string) string {
+	switch strings.ToLower(strings.TrimSpace(risk)) {
+	case "critical", "high":
+		return "error"
+	case "medium", "moderate":
+		return "warning"
+	case "low", "negligible", "info", "informational":
+		return "note"
+	case "", "ok", "none", "compliant", "no risk", "no-risk":
+		return "none"
+	}
+	return "warning"
+}
+
+// sarifBuilder collects rules and results for a single SARIF run.
+type sarifBuilder struct {
+	log   model.SarifLog
+	index map[string]int
+}
+
+func newSarifBuilder(toolVersion string) *sarifBuilder {
+	return &sarifBuilder{
+		log: model.SarifLog{
+			Schema:  model.SarifSchema,
+			Version: model.SarifVersion,
+			Runs: []model.SarifRun{{
+				Tool: model.SarifTool{Driver: model.SarifDriver{
+					Name:           "kcskit",
+					Version:        toolVersion,
+					InformationURI: "https://github.com/arturscheiner/kcskit",
+					Rules:          []model.SarifRule{},
+				}},
+				Results: []model.SarifResult{},
+			}},
+		},
+		index: map[string]int{},
+	}
+}
+
+// rule registers rule (once) and returns its index.
+func (b *sarifBuilder) rule(rule model.SarifRule) int {
+	if i, ok := b.index[rule.ID]; ok {
+		return i
+	}
+	if rule.DefaultConfiguration.Level == "" {
+		rule.DefaultConfiguration.Level = "warning"
+	}
+	run := &b.log.Runs[0]
+	run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+	b.index[rule.ID] = len(run.Tool.Driver.Rules) - 1
+	return b.index[rule.ID]
+}
+
+// add appends a result for rule located at artifact (an image or artifact reference).
+func (b *sarifBuilder) add(rule model.SarifRule, level, artifact, message string, props map[string]interface{}) {
+	idx := b.rule(rule)
+	sum := sha256.Sum256([]byte(rule.ID + "|" + artifact + "|" + message))
+	b.log.Runs[0].Results = append(b.log.Runs[0].Results, model.SarifResult{
+		RuleID:    rule.ID,
+		RuleIndex: idx,
+		Level:     level,
+		Message:   model.SarifMessage{Text: message},
+		Locations: []model.SarifLocation{{
+			PhysicalLocation: model.SarifPhysicalLocation{
+				ArtifactLocation: model.SarifArtifactLocation{URI: artifact},
+				Region:           &model.SarifRegion{StartLine: 1},
+			},
+			LogicalLocations: []model.SarifLogicalLocation{{Name: artifact, Kind: "container-image"}},
+		}},
+		PartialFingerprints: map[string]string{"kcsFinding/v1": hex.EncodeToString(sum[:16])},
+		Properties:          props,
+	})
+}
+
+// ImagesSARIF builds a SARIF log with one result per image and risk category.
+// For every category in categories (all RiskCategories when empty) the images
+// matching query plus risks[]=<category> are listed: the page set in query, or
+// every page (limit per page) when all is true.
+func ImagesSARIF(ctx context.Context, cfg model.Config, invalidCert bool, query url.Values, limit int, all bool, categories []string, toolVersion string) (model.SarifLog, string, error) {
+	if len(categories) == 0 {
+		categories = RiskCategories
+	}
+	b := newSarifBuilder(toolVersion)
+	for _, category := range categories {
+		rule, ok := riskRules[category]
+		if !ok {
+			return model.SarifLog{}, "", fmt.Errorf("unknown risk category %q (want one of %s)", category, strings.Join(RiskCategories, ", "))
+		}
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Del("risks[]")
+		q.Set("risks[]", category)
+
+		var items []model.ImageItem
+		var body string
+		var err error
+		if all {
+			items, body, _, err = ListAllImages(ctx, cfg, invalidCert, q, limit)
+		} else {
+			items, body, _, err = ListImages(ctx, cfg, invalidCert, q.Encode())
+		}
+		if err != nil {
+			return model.SarifLog{}, body, fmt.Errorf("listing images with %s: %w", category, err)
+		}
+		b.rule(rule)
+		for _, it := range items {
+			msg := fmt.Sprintf("%s: %s (risk rating: %s)", rule.ShortDescription.Text, it.Name, it.RiskRating)
+			b.add(rule, SarifLevel(it.RiskRating), it.Name, msg, map[string]interface{}{
+				"imageId":    it.ID,
+				"registry":   it.ImageRegistryName,
+				"riskRating": it.RiskRating,
+				"category":   category,
+			})
+		}
+	}
+	return b.log, "", nil
+}
+
+// CicdSARIF builds a SARIF log with one result per CI/CD scan rated above "no risk".
+func CicdSARIF(scans []model.CiCdScan, toolVersion string) model.SarifLog {
+	b := newSarifBuilder(toolVersion)
+	rule := riskRules["cicd"]
+	b.rule(rule)
+	for _, s := range scans {
+		level := SarifLevel(s.RiskRating)
+		if level == "none" {
+			continue
+		}
+		msg := fmt.Sprintf("CI/CD scan %s of %s finished with status %s and risk rating %s", s.ID, s.ArtifactName, s.Status, s.RiskRating)
+		b.add(rule, level, s.ArtifactName, msg, map[string]interface{}{
+			"scanId":     s.ID,
+			"status":     s.Status,
+			"riskRating": s.RiskRating,
+		})
+	}
+	return b.log
+}
+
+// ImageDetailSARIF builds a SARIF log with one result per finding of detail.
+// Every CVE gets its own rule; the other categories use the category rules.
+func ImageDetailSARIF(detail model.ImageDetail, toolVersion string) model.SarifLog {
+	b := newSarifBuilder(toolVersion)
+	for _, f := range ImageFindings(detail) {
+		rule := riskRules[f.Category]
+		msg := fmt.Sprintf("%s: %s in %s", rule.ShortDescription.Text, f.ID, detail.Name)
+		props := map[string]interface{}{
+			"imageId":  detail.ID,
+			"category": f.Category,
+			"severity": f.Severity,
+		}
+		if f.Category == "vulnerabilities" {
+			rule = model.SarifRule{
+				ID:               f.ID,
+				Name:             "ImageVulnerability",
+				ShortDescription: model.SarifMessage{Text: f.ID},
+				Properties:       map[string]interface{}{"tags": []string{"security", "container", "vulnerability"}},
+			}
+			if f.Title != "" {
+				rule.FullDescription = &model.SarifMessage{Text: f.Title}
+			}
+			msg = fmt.Sprintf("%s in %s %s (fixed in %s)", f.ID, f.Package, f.Installed, orNone(f.Fixed))
+			props["package"], props["installedVersion"], props["fixedVersion"] = f.Package, f.Installed, f.Fixed
+		} else if f.Path != "" {
+			msg += " at " + f.Path
+			props["path"] = f.Path
+		}
+		b.add(rule, SarifLevel(f.Severity), detail.Name, msg, props)
+	}
+	return b.log
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
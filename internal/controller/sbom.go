@@ -0,0 +1,354 @@
+package controller
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// SBOMFormats lists the formats accepted by images sbom --format.
+var SBOMFormats = []string{"cyclonedx-json", "spdx-json"}
+
+// purlTypes maps KCS package types to package URL types.
+var purlTypes = map[string]string{
+	"deb":      "deb",
+	"dpkg":     "deb",
+	"rpm":      "rpm",
+	"apk":      "apk",
+	"npm":      "npm",
+	"yarn":     "npm",
+	"node":     "npm",
+	"python":   "pypi",
+	"pip":      "pypi",
+	"pypi":     "pypi",
+	"poetry":   "pypi",
+	"gem":      "gem",
+	"bundler":  "gem",
+	"ruby":     "gem",
+	"jar":      "maven",
+	"java":     "maven",
+	"maven":    "maven",
+	"gradle":   "maven",
+	"go":       "golang",
+	"golang":   "golang",
+	"gomod":    "golang",
+	"gobinary": "golang",
+	"nuget":    "nuget",
+	"dotnet":   "nuget",
+	"cargo":    "cargo",
+	"rust":     "cargo",
+	"composer": "composer",
+	"php":      "composer",
+}
+
+// osPurlTypes are the purl types namespaced by the distribution.
+var osPurlTypes = map[string]bool{"deb": true, "rpm": true, "apk": true}
+
+// PackagePurl returns pkg.Purl, or builds a package URL from the package type,
+// name and version. distro (e.g. "debian 12") namespaces OS packages. It
+// returns "" for unknown package types.
+func PackagePurl(pkg model.ImagePackage, distro string) string {
+	if pkg.Purl != "" {
+		return pkg.Purl
+	}
+	typ, ok := purlTypes[strings.ToLower(pkg.Type)]
+	if !ok || pkg.Name == "" {
+		return ""
+	}
+
+	namespace, name := "", pkg.Name
+	switch {
+	case osPurlTypes[typ]:
+		if fields := strings.Fields(strings.ToLower(distro)); len(fields) > 0 {
+			namespace = fields[0]
+		}
+	case typ == "maven":
+		if group, artifact, ok := strings.Cut(name, ":"); ok {
+			namespace, name = group, artifact
+		}
+	case typ == "golang" || typ == "npm" || typ == "composer":
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("pkg:" + typ + "/")
+	if namespace != "" {
+		for _, seg := range strings.Split(namespace, "/") {
+			b.WriteString(url.PathEscape(seg) + "/")
+		}
+	}
+	b.WriteString(url.PathEscape(name))
+	if pkg.Version != "" {
+		b.WriteString("@" + url.PathEscape(pkg.Version))
+	}
+	return b.String()
+}
+
+// VulnerabilityLink returns the advisory URL of v, defaulting to NVD for CVEs.
+func VulnerabilityLink(v model.Vulnerability) string {
+	if v.Link != "" {
+		return v.Link
+	}
+	if strings.HasPrefix(strings.ToUpper(v.ID), "CVE-") {
+		return "https://nvd.nist.gov/vuln/detail/" + strings.ToUpper(v.ID)
+	}
+	return ""
+}
+
+// imagePackages returns the package inventory of detail with purls filled in.
+// When KCS reports no inventory, the packages named by vulnerabilities are used.
+func imagePackages(detail model.ImageDetail) []model.ImagePackage {
+	pkgs := detail.Packages
+	if len(pkgs) == 0 {
+		seen := map[string]bool{}
+		for _, v := range detail.Vulnerabilities {
+			key := v.PackageName + "@" + v.InstalledVersion
+			if v.PackageName == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			pkgs = append(pkgs, model.ImagePackage{Name: v.PackageName, Version: v.InstalledVersion, Type: v.PackageType})
+		}
+	}
+	out := make([]model.ImagePackage, len(pkgs))
+	for i, p := range pkgs {
+		p.Purl = PackagePurl(p, detail.OS)
+		out[i] = p
+	}
+	return out
+}
+
+// packageIndex maps "name@version" and "name" to the index of the package.
+func packageIndex(pkgs []model.ImagePackage) map[string]int {
+	idx := map[string]int{}
+	for i := len(pkgs) - 1; i >= 0; i-- {
+		idx[pkgs[i].Name+"@"+pkgs[i].Version] = i
+		idx[pkgs[i].Name] = i
+	}
+	return idx
+}
+
+// vulnerablePackage returns the index of the package affected by v, or -1.
+func vulnerablePackage(idx map[string]int, v model.Vulnerability) int {
+	if i, ok := idx[v.PackageName+"@"+v.InstalledVersion]; ok {
+		return i
+	}
+	if i, ok := idx[v.PackageName]; ok {
+		return i
+	}
+	return -1
+}
+
+// splitImageRef splits "registry/repo:tag" into its name and tag.
+func splitImageRef(ref string) (string, string) {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// imagePurl returns the OCI package URL of an image digest.
+func imagePurl(name, digest string) string {
+	return "pkg:oci/" + url.PathEscape(name[strings.LastIndex(name, "/")+1:]) + "@" + strings.ReplaceAll(url.PathEscape(digest), ":", "%3A")
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ImageCycloneDX builds a CycloneDX 1.5 BOM of the image packages, with the
+// vulnerabilities KCS found linked to the affected components.
+func ImageCycloneDX(detail model.ImageDetail, toolVersion string, now time.Time) model.CdxBom {
+	name, tag := splitImageRef(detail.Name)
+	image := model.CdxComponent{Type: "container", BomRef: "image", Name: name, Version: tag}
+	if detail.Digest != "" {
+		if image.Version == "" {
+			image.Version = detail.Digest
+		}
+		image.Purl = imagePurl(name, detail.Digest)
+		if alg, sum, ok := strings.Cut(detail.Digest, ":"); ok && strings.EqualFold(alg, "sha256") {
+			image.Hashes = []model.CdxHash{{Alg: "SHA-256", Content: sum}}
+		}
+	}
+	if detail.ImageRegistryName != "" {
+		image.Properties = append(image.Properties, model.CdxProperty{Name: "kcs:registry", Value: detail.ImageRegistryName})
+	}
+	if detail.ID != "" {
+		image.Properties = append(image.Properties, model.CdxProperty{Name: "kcs:imageId", Value: detail.ID})
+	}
+
+	bom := model.CdxBom{
+		BomFormat:    "CycloneDX",
+		SpecVersion:  model.CycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: model.CdxMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools:     model.CdxTools{Components: []model.CdxComponent{{Type: "application", Name: "kcskit", Version: toolVersion}}},
+			Component: image,
+		},
+		Components: []model.CdxComponent{},
+	}
+
+	pkgs := imagePackages(detail)
+	refs := make([]string, len(pkgs))
+	seen := map[string]bool{}
+	for i, p := range pkgs {
+		ref := p.Purl
+		if ref == "" || seen[ref] {
+			ref = fmt.Sprintf("component-%d", i+1)
+		}
+		seen[ref] = true
+		refs[i] = ref
+		c := model.CdxComponent{Type: "library", BomRef: ref, Name: p.Name, Version: p.Version, Purl: p.Purl}
+		for _, l := range p.Licenses {
+			c.Licenses = append(c.Licenses, model.CdxLicenses{License: model.CdxLicense{Name: l}})
+		}
+		if p.Type != "" {
+			c.Properties = []model.CdxProperty{{Name: "kcs:packageType", Value: p.Type}}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	bom.Dependencies = []model.CdxDependency{{Ref: image.BomRef, DependsOn: refs}}
+
+	idx := packageIndex(pkgs)
+	for _, v := range detail.Vulnerabilities {
+		i := vulnerablePackage(idx, v)
+		if i < 0 {
+			continue
+		}
+		cv := model.CdxVulnerability{
+			ID:          v.ID,
+			Description: v.Title,
+			Ratings:     []model.CdxRating{{Severity: cdxSeverity(v.Severity), Score: v.Cvss}},
+			Affects:     []model.CdxAffect{{Ref: refs[i]}},
+		}
+		if link := VulnerabilityLink(v); link != "" {
+			cv.Advisories = []model.CdxLink{{URL: link}}
+			if strings.HasPrefix(strings.ToUpper(v.ID), "CVE-") {
+				cv.Source = &model.CdxSource{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + strings.ToUpper(v.ID)}
+			}
+		}
+		bom.Vulnerabilities = append(bom.Vulnerabilities, cv)
+	}
+	return bom
+}
+
+// cdxSeverity maps a KCS severity to a CycloneDX severity.
+func cdxSeverity(s string) string {
+	switch s = normalizeSeverity(s); s {
+	case "critical", "high", "medium", "low":
+		return s
+	case "negligible":
+		return "info"
+	}
+	return "unknown"
+}
+
+// spdxLicenseID matches license names usable as SPDX license identifiers.
+var spdxLicenseID = regexp.MustCompile(`^[A-Za-z0-9.+-]+$`)
+
+// spdxLicense joins licenses into an SPDX expression, or NOASSERTION.
+func spdxLicense(licenses []string) string {
+	if len(licenses) == 0 {
+		return "NOASSERTION"
+	}
+	for _, l := range licenses {
+		if !spdxLicenseID.MatchString(l) {
+			return "NOASSERTION"
+		}
+	}
+	return strings.Join(licenses, " AND ")
+}
+
+// spdxIDChars matches characters not allowed in SPDX identifiers.
+var spdxIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// ImageSPDX builds an SPDX 2.3 document of the image packages. Vulnerabilities
+// are attached to the affected packages as SECURITY advisory references.
+func ImageSPDX(detail model.ImageDetail, toolVersion string, now time.Time) model.SpdxDocument {
+	name, tag := splitImageRef(detail.Name)
+	image := model.SpdxPackage{
+		Name:                  name,
+		SPDXID:                "SPDXRef-Image",
+		VersionInfo:           tag,
+		DownloadLocation:      "NOASSERTION",
+		LicenseConcluded:      "NOASSERTION",
+		LicenseDeclared:       "NOASSERTION",
+		CopyrightText:         "NOASSERTION",
+		PrimaryPackagePurpose: "CONTAINER",
+	}
+	if alg, sum, ok := strings.Cut(detail.Digest, ":"); ok && strings.EqualFold(alg, "sha256") {
+		image.Checksums = []model.SpdxChecksum{{Algorithm: "SHA256", ChecksumValue: sum}}
+		image.ExternalRefs = []model.SpdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: imagePurl(name, detail.Digest)}}
+	}
+
+	docName := detail.Name
+	if docName == "" {
+		docName = detail.ID
+	}
+	doc := model.SpdxDocument{
+		SpdxVersion:       model.SpdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              docName,
+		DocumentNamespace: "https://github.com/arturscheiner/kcskit/spdx/" + strings.Trim(spdxIDChars.ReplaceAllString(docName, "-"), "-") + "-" + newUUID(),
+		CreationInfo: model.SpdxCreationInfo{
+			Created:  now.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: kcskit-" + toolVersion},
+		},
+		DocumentDescribes: []string{image.SPDXID},
+		Packages:          []model.SpdxPackage{image},
+		Relationships: []model.SpdxRelationship{{
+			SpdxElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSpdxElement: image.SPDXID,
+		}},
+	}
+
+	pkgs := imagePackages(detail)
+	for i, p := range pkgs {
+		sp := model.SpdxPackage{
+			Name:             p.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			VersionInfo:      p.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  spdxLicense(p.Licenses),
+			CopyrightText:    "NOASSERTION",
+		}
+		if p.Purl != "" {
+			sp.ExternalRefs = append(sp.ExternalRefs, model.SpdxExternalRef{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: p.Purl})
+		}
+		doc.Packages = append(doc.Packages, sp)
+		doc.Relationships = append(doc.Relationships, model.SpdxRelationship{
+			SpdxElementID: image.SPDXID, RelationshipType: "CONTAINS", RelatedSpdxElement: sp.SPDXID,
+		})
+	}
+
+	idx := packageIndex(pkgs)
+	for _, v := range detail.Vulnerabilities {
+		i := vulnerablePackage(idx, v)
+		link := VulnerabilityLink(v)
+		if i < 0 || link == "" {
+			continue
+		}
+		p := &doc.Packages[i+1]
+		p.ExternalRefs = append(p.ExternalRefs, model.SpdxExternalRef{
+			ReferenceCategory: "SECURITY",
+			ReferenceType:     "advisory",
+			ReferenceLocator:  link,
+			Comment:           fmt.Sprintf("%s (%s)", v.ID, normalizeSeverity(v.Severity)),
+		})
+	}
+	return doc
+}
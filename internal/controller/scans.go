@@ -1,19 +1,23 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/arturscheiner/kcskit/internal/model"
-	cfgsvc "github.com/arturscheiner/kcskit/internal/service"
 )
 
 // CreateScan triggers a manual scan for an artifact in a registry.
 // Returns parsed ManualJob, raw response body and error.
-func CreateScan(cfg model.Config, invalidCert bool, artifact string, registryID string) (model.ManualJob, string, string, error) {
+func CreateScan(ctx context.Context, cfg model.Config, invalidCert bool, artifact string, registryID string) (model.ManualJob, string, string, error) {
 	var job model.ManualJob
 
-	client, err := cfgsvc.NewClient(cfg.Endpoint, cfg.Token, invalidCert, cfg.CaCert)
+	client, err := newClient(cfg, invalidCert)
 	if err != nil {
 		return job, "", "", err
 	}
@@ -24,7 +28,7 @@ func CreateScan(cfg model.Config, invalidCert bool, artifact string, registryID
 	}
 
 	endpoint := "/v1/scans"
-	status, body, err := client.PostJSON(endpoint, "", payload, nil)
+	status, body, err := client.PostJSON(ctx, endpoint, "", payload, nil)
 	if err != nil {
 		return job, string(body), endpoint, err
 	}
@@ -37,3 +41,194 @@ func CreateScan(cfg model.Config, invalidCert bool, artifact string, registryID
 	}
 	return job, string(body), endpoint, nil
 }
+
+// GetScan fetches a manual scan job by ID (GET /v1/scans/{id}).
+// Returns parsed ManualJob, raw response body, endpoint and error.
+func GetScan(ctx context.Context, cfg model.Config, invalidCert bool, id string) (model.ManualJob, string, string, error) {
+	var job model.ManualJob
+
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return job, "", "", err
+	}
+
+	endpoint := "/v1/scans/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "GET", endpoint, "", nil)
+	if err != nil {
+		return job, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return job, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	if err := json.Unmarshal(body, &job); err != nil {
+		return job, string(body), endpoint, fmt.Errorf("failed to parse scan response: %w", err)
+	}
+	return job, string(body), endpoint, nil
+}
+
+// FindImageResult looks up the scan results of artifact in /v1/images/registry,
+// matching the image name exactly; nil means the image has no results yet.
+func FindImageResult(ctx context.Context, cfg model.Config, invalidCert bool, artifact, registryID string) (*model.ImageItem, string, error) {
+	images, body, err := FindImageResults(ctx, cfg, invalidCert, registryID, []string{artifact})
+	if err != nil {
+		return nil, body, err
+	}
+	if image, ok := images[artifact]; ok {
+		return &image, "", nil
+	}
+	return nil, "", nil
+}
+
+// FindImageResults looks up the scan results of several artifacts with one walk
+// of /v1/images/registry, keyed by the exactly matching image name. The API
+// cannot filter by image name ("name" is the registry name), so with registryID
+// the walk is narrowed to the images of that registry; otherwise every page is walked.
+func FindImageResults(ctx context.Context, cfg model.Config, invalidCert bool, registryID string, artifacts []string) (map[string]model.ImageItem, string, error) {
+	query := url.Values{}
+	if registryID != "" {
+		query.Set("registry", registryID)
+	}
+	items, body, _, err := ListAllImages(ctx, cfg, invalidCert, query, 100)
+	if err != nil {
+		return nil, body, err
+	}
+	want := map[string]bool{}
+	for _, a := range artifacts {
+		want[a] = true
+	}
+	found := map[string]model.ImageItem{}
+	for _, it := range items {
+		if _, dup := found[it.Name]; want[it.Name] && !dup {
+			found[it.Name] = it
+		}
+	}
+	return found, "", nil
+}
+
+// ListScans calls GET /v1/scans with query (URL-encoded page, limit and filters).
+// Returns parsed jobs, raw response body, endpoint and error.
+func ListScans(ctx context.Context, cfg model.Config, invalidCert bool, rawQuery string) ([]model.ManualJob, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/scans"
+	status, body, err := client.Do(ctx, "GET", endpoint, rawQuery, nil)
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+
+	var jr model.ManualJobsResponse
+	if err := json.Unmarshal(body, &jr); err != nil {
+		return nil, string(body), endpoint, fmt.Errorf("failed to parse scans JSON: %w", err)
+	}
+	return jr.Items, string(body), endpoint, nil
+}
+
+// ListAllScans walks every page of /v1/scans with limit items per page and
+// returns the merged jobs, a merged JSON body and the endpoint.
+func ListAllScans(ctx context.Context, cfg model.Config, invalidCert bool, query url.Values, limit int) ([]model.ManualJob, string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	endpoint := "/v1/scans"
+	items, total, body, err := listAll(ctx, client, endpoint, query, limit, "scans", func(r *model.ManualJobsResponse) ([]model.ManualJob, int) {
+		return r.Items, r.Total
+	})
+	if err != nil {
+		return nil, string(body), endpoint, err
+	}
+
+	merged, err := json.Marshal(model.ManualJobsResponse{Total: total, Page: 1, Items: items})
+	if err != nil {
+		return nil, "", endpoint, err
+	}
+	return items, string(merged), endpoint, nil
+}
+
+// FilterScans keeps the jobs matching every non-empty filter: status and scanner
+// compare case-insensitively, artifact matches any part of the artifact name.
+func FilterScans(jobs []model.ManualJob, status, scanner, artifact string) []model.ManualJob {
+	var out []model.ManualJob
+	for _, j := range jobs {
+		if status != "" && !strings.EqualFold(j.Status, status) {
+			continue
+		}
+		if scanner != "" && !strings.EqualFold(j.ScannerName, scanner) {
+			continue
+		}
+		if artifact != "" && !strings.Contains(strings.ToLower(j.ArtifactName), strings.ToLower(artifact)) {
+			continue
+		}
+		out = append(out, j)
+	}
+	return out
+}
+
+// CancelScan cancels a manual scan job (DELETE /v1/scans/{id}).
+// Returns the raw response body, endpoint and error.
+func CancelScan(ctx context.Context, cfg model.Config, invalidCert bool, id string) (string, string, error) {
+	client, err := newClient(cfg, invalidCert)
+	if err != nil {
+		return "", "", err
+	}
+
+	endpoint := "/v1/scans/" + url.PathEscape(id)
+	status, body, err := client.Do(ctx, "DELETE", endpoint, "", nil)
+	if err != nil {
+		return string(body), endpoint, err
+	}
+	if status < 200 || status >= 300 {
+		return string(body), endpoint, fmt.Errorf("received HTTP %d", status)
+	}
+	return string(body), endpoint, nil
+}
+
+// WaitForScan polls job every interval until it reaches a terminal status and
+// then looks up the scan results of its artifact among the images of registryID
+// (all images when empty), unless the job failed. progress, when not nil, is
+// called with the job after every poll.
+func WaitForScan(ctx context.Context, cfg model.Config, invalidCert bool, job model.ManualJob, registryID string, interval time.Duration, progress func(model.ManualJob)) (model.ScanResult, error) {
+	artifact := job.ArtifactName
+	job, err := waitForJob(ctx, cfg, invalidCert, job, interval, progress)
+	result := model.ScanResult{ManualJob: job}
+	if err != nil || IsFailedStatus(job.Status) {
+		return result, err
+	}
+
+	if job.ArtifactName != "" {
+		artifact = job.ArtifactName
+	}
+	if artifact == "" {
+		return result, nil
+	}
+	image, body, err := FindImageResult(ctx, cfg, invalidCert, artifact, registryID)
+	if err != nil {
+		return result, errors.New(errorWithBody(err, body))
+	}
+	result.Image = image
+	return result, nil
+}
+
+// waitForJob polls job every interval until it reaches a terminal status.
+func waitForJob(ctx context.Context, cfg model.Config, invalidCert bool, job model.ManualJob, interval time.Duration, progress func(model.ManualJob)) (model.ManualJob, error) {
+	err := Poll(ctx, interval, func(ctx context.Context) (bool, error) {
+		j, body, _, err := GetScan(ctx, cfg, invalidCert, job.ID)
+		if err != nil {
+			return false, errors.New(errorWithBody(err, body))
+		}
+		job = j
+		if progress != nil {
+			progress(job)
+		}
+		return IsTerminalStatus(job.Status), nil
+	})
+	return job, err
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+func TestFindImageResults(t *testing.T) {
+	images := []model.ImageItem{
+		{ID: "i1", Name: "app:1", ImageRegistryName: "harbor"},
+		{ID: "i2", Name: "app:1.1", ImageRegistryName: "harbor"},
+		{ID: "i3", Name: "repo/app:1", ImageRegistryName: "gitlab"},
+	}
+	registries := map[string]string{"r1": "harbor", "r2": "gitlab"}
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("registry"))
+		var items []model.ImageItem
+		for _, it := range images {
+			if reg := r.URL.Query().Get("registry"); reg == "" || registries[reg] == it.ImageRegistryName {
+				items = append(items, it)
+			}
+		}
+		if r.URL.Query().Get("page") != "1" {
+			items = nil
+		}
+		json.NewEncoder(w).Encode(model.ImagesResponse{Total: len(items), Items: items})
+	}))
+	defer srv.Close()
+	cfg := model.Config{Endpoint: srv.URL, Token: "t"}
+
+	tests := []struct {
+		name       string
+		registryID string
+		artifacts  []string
+		want       map[string]string
+	}{
+		{"exact names", "r1", []string{"app:1", "app:2"}, map[string]string{"app:1": "i1"}},
+		{"other registry", "r2", []string{"app:1", "repo/app:1"}, map[string]string{"repo/app:1": "i3"}},
+		{"all registries", "", []string{"app:1.1", "repo/app:1"}, map[string]string{"app:1.1": "i2", "repo/app:1": "i3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries = nil
+			found, _, err := FindImageResults(context.Background(), cfg, false, tt.registryID, tt.artifacts)
+			if err != nil {
+				t.Fatalf("FindImageResults: %v", err)
+			}
+			got := map[string]string{}
+			for name, it := range found {
+				got[name] = it.ID
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("FindImageResults() = %v, want %v", got, tt.want)
+			}
+			for name, id := range tt.want {
+				if got[name] != id {
+					t.Errorf("FindImageResults()[%q] = %q, want %q", name, got[name], id)
+				}
+			}
+			if len(queries) != 1 || queries[0] != tt.registryID {
+				t.Errorf("registry filters sent = %q, want one walk with %q", queries, tt.registryID)
+			}
+		})
+	}
+}
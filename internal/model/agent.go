@@ -0,0 +1,41 @@
+package model
+
+// AgentGroup is a group of KCS agents deployed to one cluster.
+type AgentGroup struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	ClusterName    string `json:"clusterName"`
+	Orchestrator   string `json:"orchestrator"`
+	AgentCount     int    `json:"agentCount"`
+	ConnectedCount int    `json:"connectedCount"`
+}
+
+type AgentGroupsResponse struct {
+	Total int          `json:"total"`
+	Page  int          `json:"page"`
+	Items []AgentGroup `json:"items"`
+}
+
+// AgentGroupDetail is an agent group with its agents (GET /v1/agent-groups/{id}).
+type AgentGroupDetail struct {
+	AgentGroup
+	Agents []Agent `json:"agents"`
+}
+
+// Agent is a single agent (node agent or cluster agent) of an agent group.
+type Agent struct {
+	ID             string `json:"id"`
+	AgentGroupId   string `json:"agentGroupId"`
+	AgentGroupName string `json:"agentGroupName"`
+	NodeName       string `json:"nodeName"`
+	Type           string `json:"type"`
+	Version        string `json:"version"`
+	Status         string `json:"status"`
+	LastHeartbeat  string `json:"lastHeartbeat"`
+}
+
+type AgentsResponse struct {
+	Total int     `json:"total"`
+	Page  int     `json:"page"`
+	Items []Agent `json:"items"`
+}
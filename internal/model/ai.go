@@ -1,9 +1,30 @@
 package model
 
+import "time"
+
 type OllamaRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Stream   bool      `json:"stream"`
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Options  *OllamaOptions `json:"options,omitempty"`
+}
+
+// OllamaOptions are the model parameters of an Ollama request.
+type OllamaOptions struct {
+	NumCtx int `json:"num_ctx,omitempty"`
+}
+
+// OllamaShowRequest asks /api/show for the details of a model.
+type OllamaShowRequest struct {
+	Model string `json:"model"`
+}
+
+// OllamaShowResponse is the part of the /api/show response kcskit uses:
+// Parameters holds the Modelfile parameters ("num_ctx 8192" lines), ModelInfo
+// the model metadata such as "llama.context_length".
+type OllamaShowResponse struct {
+	Parameters string         `json:"parameters"`
+	ModelInfo  map[string]any `json:"model_info"`
 }
 
 type Message struct {
@@ -17,6 +38,8 @@ type OllamaResponse struct {
 	Message         Message `json:"message"`
 	Done            bool    `json:"done"`
 	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
 }
 
 type OllamaHeader struct {
@@ -34,4 +57,55 @@ type TokenizeRequest struct {
 
 type TokenizeResponse struct {
 	Tokens []int `json:"tokens"`
-}
\ No newline at end of file
+}
+
+// ChatReply is the complete answer of an AI provider to a chat request. Token
+// counts are 0 when the backend does not report them.
+type ChatReply struct {
+	Model            string
+	Content          string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// OpenAIChatRequest is a request to an OpenAI-compatible /chat/completions API.
+type OpenAIChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+// OpenAIChatChunk is one server-sent event of a streamed chat completion.
+type OpenAIChatChunk struct {
+	Model   string         `json:"model"`
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   *OpenAIUsage   `json:"usage"`
+	Error   *OpenAIError   `json:"error"`
+}
+
+type OpenAIChoice struct {
+	Delta        Message `json:"delta"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type OpenAIError struct {
+	Message string `json:"message"`
+}
+
+// ChatTranscript is an AI chat session as saved to disk.
+type ChatTranscript struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Command  string    `json:"command,omitempty"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+	// Context is the number of leading messages holding the command output
+	// (and the report it was asked for), kept when the history is cleared.
+	Context  int       `json:"context"`
+	Messages []Message `json:"messages"`
+}
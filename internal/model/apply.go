@@ -0,0 +1,27 @@
+package model
+
+// ResourceSet is the desired state read from YAML specs, grouped by kind.
+type ResourceSet struct {
+	Registries []RegistrySpec `json:"registries,omitempty"`
+}
+
+// FieldChange is one field that differs between the live and the desired resource.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// PlanAction is one step of an apply plan.
+type PlanAction struct {
+	// Action is create, update, delete or unchanged.
+	Action  string        `json:"action"`
+	Kind    string        `json:"kind"`
+	Name    string        `json:"name"`
+	ID      string        `json:"id,omitempty"`
+	Changes []FieldChange `json:"changes,omitempty"`
+	// Result is set once the action was applied ("created", "failed: ...", ...).
+	Result string `json:"result,omitempty"`
+
+	Registry *RegistrySpec `json:"-"`
+}
@@ -15,3 +15,58 @@ type CiCdScan struct {
 	Status       string    `json:"status"`
 	CreatedAt    time.Time `json:"createdAt"`
 }
+
+// CiCdScanDetail is a single CI/CD scan as returned by /v1/scans/ci-cd/{id}.
+type CiCdScanDetail struct {
+	CiCdScan
+	BuildPipeline string          `json:"buildPipeline"`
+	BuildNumber   string          `json:"buildNumber"`
+	UpdatedAt     string          `json:"updatedAt"`
+	Digest        string          `json:"digest"`
+	RiskSummary   CiCdRiskSummary `json:"riskSummary"`
+
+	Vulnerabilities   []Vulnerability        `json:"vulnerabilities"`
+	Malware           []MalwareFinding       `json:"malware"`
+	SensitiveData     []SensitiveDataFinding `json:"sensitiveData"`
+	Misconfigurations []Misconfiguration     `json:"misconfigurations"`
+	PolicyDecisions   []PolicyDecision       `json:"policyDecisions"`
+}
+
+// PolicyDecision is the verdict of one KCS security policy on a CI/CD scan.
+type PolicyDecision struct {
+	PolicyID   string `json:"policyId"`
+	PolicyName string `json:"policyName"`
+	Action     string `json:"action"`
+	Result     string `json:"result"`
+	Reason     string `json:"reason"`
+}
+
+// CiCdRiskSummary counts findings per risk category.
+type CiCdRiskSummary struct {
+	Vulnerabilities   int `json:"vulnerabilities"`
+	Malware           int `json:"malware"`
+	SensitiveData     int `json:"sensitiveData"`
+	Misconfigurations int `json:"misconfigurations"`
+}
+
+// Counts returns the summary keyed by the risk category names used by --risks.
+func (s CiCdRiskSummary) Counts() map[string]int {
+	return map[string]int{
+		"vulnerabilities":  s.Vulnerabilities,
+		"malware":          s.Malware,
+		"sensitive-data":   s.SensitiveData,
+		"misconfiguration": s.Misconfigurations,
+	}
+}
+
+// GateResult is the outcome of evaluating a CI/CD scan against a quality gate.
+type GateResult struct {
+	ScanID     string         `json:"scanId"`
+	Artifact   string         `json:"artifact"`
+	Status     string         `json:"status"`
+	RiskRating string         `json:"riskRating"`
+	Threshold  string         `json:"threshold"`
+	Categories map[string]int `json:"categories"`
+	Passed     bool           `json:"passed"`
+	Reasons    []string       `json:"reasons"`
+}
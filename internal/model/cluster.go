@@ -14,3 +14,49 @@ type ClusterResponse struct {
 	Page  int           `json:"page"`
 	Items []ClusterItem `json:"items"`
 }
+
+// ClusterDetail is a single cluster with its agent group (GET /v1/clusters/{id}).
+type ClusterDetail struct {
+	ClusterItem
+	AgentGroupName      string `json:"agentGroupName"`
+	OrchestratorVersion string `json:"orchestratorVersion"`
+	Nodes               int    `json:"nodes"`
+	Workloads           int    `json:"workloads"`
+	CreatedAt           string `json:"createdAt"`
+}
+
+type NamespaceItem struct {
+	Name       string `json:"name"`
+	Workloads  int    `json:"workloads"`
+	Images     int    `json:"images"`
+	RiskRating string `json:"riskRating"`
+}
+
+type NamespacesResponse struct {
+	Total int             `json:"total"`
+	Page  int             `json:"page"`
+	Items []NamespaceItem `json:"items"`
+}
+
+// WorkloadItem is a workload running in a cluster, with the images of its containers.
+type WorkloadItem struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Kind       string          `json:"kind"`
+	Namespace  string          `json:"namespace"`
+	Replicas   int             `json:"replicas"`
+	RiskRating string          `json:"riskRating"`
+	Images     []WorkloadImage `json:"images"`
+}
+
+type WorkloadImage struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	RiskRating string `json:"riskRating"`
+}
+
+type WorkloadsResponse struct {
+	Total int            `json:"total"`
+	Page  int            `json:"page"`
+	Items []WorkloadItem `json:"items"`
+}
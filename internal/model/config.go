@@ -1,9 +1,75 @@
 package model
 
+import "time"
+
 type Config struct {
-	Token            string `yaml:"token"`
+	Token            string `yaml:"token,omitempty"`
 	Endpoint         string `yaml:"endpoint"`
 	CaCert           string `yaml:"ca_cert,omitempty"`
 	AiOllamaEndpoint string `yaml:"ai_ollama_endpoint,omitempty"`
 	AiOllamaModel    string `yaml:"ai_ollama_model,omitempty"`
+
+	// AiProvider selects the AI backend used by -o ai: "ollama" or "openai" (any
+	// OpenAI-compatible chat completions API). Empty picks the configured one.
+	AiProvider string `yaml:"ai_provider,omitempty"`
+	// AiOllamaApiKey is sent as a bearer token, for Ollama behind an authenticating proxy.
+	AiOllamaApiKey string `yaml:"ai_ollama_api_key,omitempty"`
+	// AiOllamaApiKeyRef points to AiOllamaApiKey in a secret backend (see TokenRef).
+	AiOllamaApiKeyRef string `yaml:"ai_ollama_api_key_ref,omitempty"`
+	AiOpenAIEndpoint  string `yaml:"ai_openai_endpoint,omitempty"`
+	AiOpenAIModel     string `yaml:"ai_openai_model,omitempty"`
+	AiOpenAIApiKey    string `yaml:"ai_openai_api_key,omitempty"`
+	// AiOpenAIApiKeyRef points to AiOpenAIApiKey in a secret backend (see TokenRef).
+	AiOpenAIApiKeyRef string `yaml:"ai_openai_api_key_ref,omitempty"`
+	// AiOpenAIAuthHeader is the header carrying AiOpenAIApiKey: "Authorization"
+	// (the default) sends "Bearer <key>", any other header (e.g. "api-key") the raw key.
+	AiOpenAIAuthHeader string `yaml:"ai_openai_auth_header,omitempty"`
+	// AiContextLength caps the context window (in tokens) AI reports are planned
+	// for. Zero uses the window reported by Ollama, or 8192 for other backends.
+	AiContextLength int `yaml:"ai_context_length,omitempty"`
+
+	// TokenRef points to the token in a secret backend ("keyring:<name>",
+	// "age-file:<path>" or "exec:<command>") instead of storing it in Token.
+	TokenRef string `yaml:"token_ref,omitempty"`
+	// SecretBackend is the backend the token and the AI API keys of this context
+	// are stored in. The read-only exec backend only provides the token.
+	SecretBackend string `yaml:"secret_backend,omitempty"`
+
+	// HTTP client tuning; zero values fall back to the client defaults.
+	HTTPTimeout        time.Duration `yaml:"http_timeout,omitempty"`
+	RetryMaxAttempts   int           `yaml:"retry_max_attempts,omitempty"`
+	RetryBackoff       time.Duration `yaml:"retry_backoff,omitempty"`
+	RetryMaxBackoff    time.Duration `yaml:"retry_max_backoff,omitempty"`
+	RetryNonIdempotent *bool         `yaml:"retry_non_idempotent,omitempty"`
+}
+
+// ConfigFile is the on-disk layout of $HOME/.kcskit/config: a set of named
+// profiles (contexts) and the one used by default.
+type ConfigFile struct {
+	CurrentContext string         `yaml:"current-context"`
+	Contexts       []NamedContext `yaml:"contexts"`
+}
+
+// NamedContext is a Config stored under a profile name.
+type NamedContext struct {
+	Name   string `yaml:"name"`
+	Config `yaml:",inline"`
+}
+
+// Context returns the profile called name, or nil if it does not exist.
+func (f *ConfigFile) Context(name string) *NamedContext {
+	for i := range f.Contexts {
+		if f.Contexts[i].Name == name {
+			return &f.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// ConfigValue is one effective configuration setting and where it came from
+// ("file", "env" or "flag", with details).
+type ConfigValue struct {
+	Key    string `json:"key" yaml:"key"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
 }
@@ -17,3 +17,70 @@ type ImagesResponse struct {
 	Page  int         `json:"page"`
 	Items []ImageItem `json:"items"`
 }
+
+// ImageDetail is the full scan result of one image (GET /v1/images/registry/{id}).
+type ImageDetail struct {
+	ImageItem
+	Digest            string                 `json:"digest"`
+	ScannedAt         string                 `json:"scannedAt"`
+	OS                string                 `json:"os"`
+	Vulnerabilities   []Vulnerability        `json:"vulnerabilities"`
+	Malware           []MalwareFinding       `json:"malware"`
+	SensitiveData     []SensitiveDataFinding `json:"sensitiveData"`
+	Misconfigurations []Misconfiguration     `json:"misconfigurations"`
+	Packages          []ImagePackage         `json:"packages"`
+}
+
+// ImagePackage is one entry of the package inventory of an image.
+type ImagePackage struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Type     string   `json:"type"`
+	Purl     string   `json:"purl"`
+	Licenses []string `json:"licenses"`
+}
+
+type Vulnerability struct {
+	ID               string  `json:"id"`
+	Severity         string  `json:"severity"`
+	PackageName      string  `json:"packageName"`
+	PackageType      string  `json:"packageType"`
+	InstalledVersion string  `json:"installedVersion"`
+	FixedVersion     string  `json:"fixedVersion"`
+	Title            string  `json:"title"`
+	Cvss             float64 `json:"cvss"`
+	Link             string  `json:"link"`
+}
+
+type MalwareFinding struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Severity string `json:"severity"`
+}
+
+type SensitiveDataFinding struct {
+	Type     string `json:"type"`
+	Path     string `json:"path"`
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+}
+
+type Misconfiguration struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Severity   string `json:"severity"`
+	Path       string `json:"path"`
+	Resolution string `json:"resolution"`
+}
+
+// ImageFinding is a single finding of any category, flattened for tables and filters.
+type ImageFinding struct {
+	Category  string `json:"category"`
+	ID        string `json:"id"`
+	Severity  string `json:"severity"`
+	Package   string `json:"package,omitempty"`
+	Installed string `json:"installed,omitempty"`
+	Fixed     string `json:"fixed,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
@@ -18,3 +18,28 @@ type RegistryResponse struct {
 	Page  int            `json:"page"`
 	Items []RegistryItem `json:"items"`
 }
+
+// RegistrySpec is the desired state of a registry integration, read from
+// flags or a YAML spec (kind: Registry). It marshals to the API payload.
+type RegistrySpec struct {
+	Kind         string `yaml:"kind,omitempty" json:"-"`
+	RegistryName string `yaml:"name" json:"registryName"`
+	RegistryType string `yaml:"type" json:"registryType"`
+	RegistryUrl  string `yaml:"url" json:"registryUrl"`
+	ApiUrl       string `yaml:"apiUrl,omitempty" json:"apiUrl,omitempty"`
+	Description  string `yaml:"description,omitempty" json:"description,omitempty"`
+	RegistryAuth `yaml:"auth"`
+}
+
+// RegistryAuth holds the credentials of a registry integration. Which fields
+// are required depends on the authentication type.
+type RegistryAuth struct {
+	AuthenticationType string `yaml:"type" json:"authenticationType"`
+	Username           string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password           string `yaml:"password,omitempty" json:"password,omitempty"`
+	Token              string `yaml:"token,omitempty" json:"token,omitempty"`
+	AccessKeyID        string `yaml:"accessKeyId,omitempty" json:"accessKeyId,omitempty"`
+	SecretAccessKey    string `yaml:"secretAccessKey,omitempty" json:"secretAccessKey,omitempty"`
+	Region             string `yaml:"region,omitempty" json:"region,omitempty"`
+	ServiceAccountKey  string `yaml:"serviceAccountKey,omitempty" json:"serviceAccountKey,omitempty"`
+}
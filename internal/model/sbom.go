@@ -0,0 +1,141 @@
+package model
+
+// CycloneDX 1.5 (JSON) subset used by images sbom.
+
+const CycloneDXSpecVersion = "1.5"
+
+type CdxBom struct {
+	BomFormat       string             `json:"bomFormat"`
+	SpecVersion     string             `json:"specVersion"`
+	SerialNumber    string             `json:"serialNumber"`
+	Version         int                `json:"version"`
+	Metadata        CdxMetadata        `json:"metadata"`
+	Components      []CdxComponent     `json:"components"`
+	Dependencies    []CdxDependency    `json:"dependencies,omitempty"`
+	Vulnerabilities []CdxVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type CdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     CdxTools     `json:"tools"`
+	Component CdxComponent `json:"component"`
+}
+
+type CdxTools struct {
+	Components []CdxComponent `json:"components"`
+}
+
+type CdxComponent struct {
+	Type       string        `json:"type"`
+	BomRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Purl       string        `json:"purl,omitempty"`
+	Licenses   []CdxLicenses `json:"licenses,omitempty"`
+	Hashes     []CdxHash     `json:"hashes,omitempty"`
+	Properties []CdxProperty `json:"properties,omitempty"`
+}
+
+type CdxLicenses struct {
+	License CdxLicense `json:"license"`
+}
+
+type CdxLicense struct {
+	Name string `json:"name"`
+}
+
+type CdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type CdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+type CdxVulnerability struct {
+	BomRef      string      `json:"bom-ref,omitempty"`
+	ID          string      `json:"id"`
+	Source      *CdxSource  `json:"source,omitempty"`
+	Ratings     []CdxRating `json:"ratings,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Advisories  []CdxLink   `json:"advisories,omitempty"`
+	Affects     []CdxAffect `json:"affects"`
+}
+
+type CdxSource struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type CdxRating struct {
+	Severity string  `json:"severity"`
+	Score    float64 `json:"score,omitempty"`
+}
+
+type CdxLink struct {
+	URL string `json:"url"`
+}
+
+type CdxAffect struct {
+	Ref string `json:"ref"`
+}
+
+// SPDX 2.3 (JSON) subset used by images sbom.
+
+const SpdxVersion = "SPDX-2.3"
+
+type SpdxDocument struct {
+	SpdxVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SpdxCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []SpdxPackage      `json:"packages"`
+	Relationships     []SpdxRelationship `json:"relationships"`
+}
+
+type SpdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SpdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Checksums             []SpdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []SpdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type SpdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type SpdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+	Comment           string `json:"comment,omitempty"`
+}
+
+type SpdxRelationship struct {
+	SpdxElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
+}
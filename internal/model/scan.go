@@ -9,3 +9,35 @@ type ManualJob struct {
 	CreatedAt    string `json:"createdAt"`
 	UpdatedAt    string `json:"updatedAt"`
 }
+
+// ScanResult is a finished manual scan job together with the scan results of its artifact.
+type ScanResult struct {
+	ManualJob
+	Image *ImageItem `json:"image,omitempty"`
+}
+
+// ManualJobsResponse is a page of manual scan jobs (GET /v1/scans).
+type ManualJobsResponse struct {
+	Total int         `json:"total"`
+	Page  int         `json:"page"`
+	Items []ManualJob `json:"items"`
+}
+
+// ScanTarget is an artifact to scan, resolved to the registry that hosts it.
+type ScanTarget struct {
+	Ref          string `json:"ref"`
+	RegistryID   string `json:"registryId"`
+	RegistryName string `json:"registryName"`
+	Artifact     string `json:"artifact"`
+}
+
+// BulkScanResult is the outcome of submitting (and optionally waiting for) one scan of a batch.
+type BulkScanResult struct {
+	Ref        string `json:"ref"`
+	Registry   string `json:"registry"`
+	Artifact   string `json:"artifact"`
+	JobID      string `json:"jobId,omitempty"`
+	Status     string `json:"status"`
+	RiskRating string `json:"riskRating,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,305 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a parsed kubectl-style JSONPath template such as
+// "{.items[*].name}" or "{range .items[*]}{.id}{\"\\t\"}{.name}{\"\\n\"}{end}".
+//
+// Supported: text outside braces, quoted string literals, field access (.name,
+// ['name']), indexes ([0], [-1]), wildcards ([*], .*) and {range <path>}...{end}
+// blocks. Filters ([?(...)]), slices ([0:2]), unions ([0,1]) and recursive
+// descent (..) are rejected.
+type JSONPath struct {
+	nodes []jpNode
+}
+
+type jpNode struct {
+	text     string   // literal text (when path is nil and children is nil)
+	path     []string // path segments: field names, "[n]" or "*"
+	isRange  bool
+	children []jpNode
+}
+
+// ParseJSONPath parses a JSONPath template. A bare expression without braces
+// (e.g. ".name") is accepted as a single expression.
+func ParseJSONPath(tmpl string) (*JSONPath, error) {
+	tmpl = strings.TrimSpace(tmpl)
+	if tmpl != "" && !strings.Contains(tmpl, "{") {
+		tmpl = "{" + tmpl + "}"
+	}
+	nodes, rest, err := parseNodes(tmpl, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("jsonpath: unexpected {end}")
+	}
+	return &JSONPath{nodes: nodes}, nil
+}
+
+// parseNodes parses until end of input or, when inRange, until the matching {end}.
+// It returns the remaining input after {end}.
+func parseNodes(s string, inRange bool) ([]jpNode, string, error) {
+	var nodes []jpNode
+	for s != "" {
+		open := strings.Index(s, "{")
+		if open == -1 {
+			nodes = append(nodes, jpNode{text: s})
+			s = ""
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jpNode{text: s[:open]})
+		}
+		end := closingBrace(s, open)
+		if end == -1 {
+			return nil, "", fmt.Errorf("jsonpath: unclosed '{' in %q", s)
+		}
+		expr := strings.TrimSpace(s[open+1 : end])
+		s = s[end+1:]
+
+		switch {
+		case expr == "end":
+			if !inRange {
+				return nodes, "{end}" + s, nil
+			}
+			return nodes, s, nil
+		case strings.HasPrefix(expr, "range "):
+			path, err := parsePath(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			children, rest, err := parseNodes(s, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jpNode{path: path, isRange: true, children: children})
+			s = rest
+		case strings.HasPrefix(expr, `"`) || strings.HasPrefix(expr, "'"):
+			if strings.HasPrefix(expr, "'") {
+				expr = `"` + strings.ReplaceAll(strings.Trim(expr, "'"), `"`, `\"`) + `"`
+			}
+			lit, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, "", fmt.Errorf("jsonpath: invalid string literal %s", expr)
+			}
+			nodes = append(nodes, jpNode{text: lit})
+		default:
+			path, err := parsePath(expr)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jpNode{path: path})
+		}
+	}
+	if inRange {
+		return nil, "", fmt.Errorf("jsonpath: range without {end}")
+	}
+	return nodes, "", nil
+}
+
+// closingBrace returns the index of the '}' closing the '{' at open, skipping quoted strings.
+func closingBrace(s string, open int) int {
+	var quote byte
+	for i := open + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// parsePath splits an expression like "$.items[*].name" into segments.
+func parsePath(expr string) ([]string, error) {
+	expr = strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	if expr == "" || expr == "." || expr == "@" {
+		return []string{}, nil
+	}
+	if expr[0] != '.' && expr[0] != '[' {
+		return nil, fmt.Errorf("jsonpath: expression %q must start with '.'", expr)
+	}
+
+	var segs []string
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			if strings.HasPrefix(expr, "[") {
+				// ".[*]" on a top-level array
+				continue
+			}
+			n := strings.IndexAny(expr, ".[")
+			if n == -1 {
+				n = len(expr)
+			}
+			if strings.HasPrefix(expr, ".") {
+				return nil, fmt.Errorf("jsonpath: recursive descent '..' is not supported")
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("jsonpath: empty field name")
+			}
+			segs = append(segs, expr[:n])
+			expr = expr[n:]
+		case '[':
+			n := strings.Index(expr, "]")
+			if n == -1 {
+				return nil, fmt.Errorf("jsonpath: unclosed '['")
+			}
+			idx := strings.TrimSpace(expr[1:n])
+			if len(idx) >= 2 && (idx[0] == '\'' || idx[0] == '"') && idx[len(idx)-1] == idx[0] {
+				// quoted field name, e.g. ['app.kubernetes.io/name']
+				segs = append(segs, idx[1:len(idx)-1])
+				expr = expr[n+1:]
+				continue
+			}
+			switch {
+			case idx == "*":
+				segs = append(segs, "*")
+			case strings.HasPrefix(idx, "?"):
+				return nil, fmt.Errorf("jsonpath: filter %q is not supported", expr[:n+1])
+			case strings.Contains(idx, ":"):
+				return nil, fmt.Errorf("jsonpath: slice %q is not supported", expr[:n+1])
+			case strings.Contains(idx, ","):
+				return nil, fmt.Errorf("jsonpath: union %q is not supported", expr[:n+1])
+			case idx == "":
+				return nil, fmt.Errorf("jsonpath: empty '[]'")
+			default:
+				if _, err := strconv.Atoi(idx); err == nil {
+					segs = append(segs, "["+idx+"]")
+				} else {
+					segs = append(segs, idx)
+				}
+			}
+			expr = expr[n+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath: unexpected %q", expr)
+		}
+	}
+	return segs, nil
+}
+
+// Execute renders the template against data (decoded JSON).
+// Multiple results of one expression are separated by spaces.
+func (j *JSONPath) Execute(data any) (string, error) {
+	var b strings.Builder
+	if err := execNodes(&b, j.nodes, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func execNodes(b *strings.Builder, nodes []jpNode, data any) error {
+	for _, n := range nodes {
+		switch {
+		case n.isRange:
+			for _, v := range lookup(data, n.path) {
+				if err := execNodes(b, n.children, v); err != nil {
+					return err
+				}
+			}
+		case n.path != nil:
+			for i, v := range lookup(data, n.path) {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				b.WriteString(formatValue(v))
+			}
+		default:
+			b.WriteString(n.text)
+		}
+	}
+	return nil
+}
+
+// Lookup evaluates a single path expression (e.g. ".riskRating") against data
+// and returns the first result, or nil.
+func Lookup(data any, expr string) (any, error) {
+	path, err := parsePath(strings.Trim(strings.TrimSpace(expr), "{}"))
+	if err != nil {
+		return nil, err
+	}
+	res := lookup(data, path)
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res[0], nil
+}
+
+// lookup walks path over data, fanning out on wildcards.
+func lookup(data any, path []string) []any {
+	cur := []any{data}
+	for _, seg := range path {
+		var next []any
+		for _, v := range cur {
+			switch {
+			case seg == "*":
+				switch t := v.(type) {
+				case []any:
+					next = append(next, t...)
+				case map[string]any:
+					for _, k := range sortedKeys(t) {
+						next = append(next, t[k])
+					}
+				}
+			case strings.HasPrefix(seg, "["):
+				arr, ok := v.([]any)
+				if !ok {
+					continue
+				}
+				i, _ := strconv.Atoi(seg[1 : len(seg)-1])
+				if i < 0 {
+					i += len(arr)
+				}
+				if i >= 0 && i < len(arr) {
+					next = append(next, arr[i])
+				}
+			default:
+				if m, ok := v.(map[string]any); ok {
+					if x, ok := m[seg]; ok {
+						next = append(next, x)
+					}
+				}
+			}
+		}
+		cur = next
+	}
+	return cur
+}
+
+// formatValue renders a decoded JSON value as text: strings and scalars as-is,
+// null as empty, objects and arrays as compact JSON.
+func formatValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case json.Number:
+		return t.String()
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,146 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const jsonpathTestDoc = `{
+	"total": 2,
+	"items": [
+		{"id": "a", "name": "nginx", "risk": 7.5, "ok": true, "labels": {"app.kubernetes.io/name": "web", "tier": "front"}, "tags": ["1.25", "latest"]},
+		{"id": "b", "name": "redis", "risk": null, "ok": false, "labels": {"tier": "back"}, "tags": []}
+	]
+}`
+
+func decodeJSON(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid test JSON: %v", err)
+	}
+	return v
+}
+
+func TestJSONPathExecute(t *testing.T) {
+	doc := decodeJSON(t, jsonpathTestDoc)
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"bare expression", ".total", "2"},
+		{"dollar root", "{$.total}", "2"},
+		{"root", "{.}", `{"items":[{"id":"a","labels":{"app.kubernetes.io/name":"web","tier":"front"},"name":"nginx","ok":true,"risk":7.5,"tags":["1.25","latest"]},{"id":"b","labels":{"tier":"back"},"name":"redis","ok":false,"risk":null,"tags":[]}],"total":2}`},
+		{"field", "{.items[0].name}", "nginx"},
+		{"negative index", "{.items[-1].id}", "b"},
+		{"index out of range", "{.items[5].id}", ""},
+		{"wildcard", "{.items[*].id}", "a b"},
+		{"dot wildcard", "{.items[0].labels.*}", "web front"},
+		{"quoted field", "{.items[0].labels['app.kubernetes.io/name']}", "web"},
+		{"double quoted field", `{.items[0].labels["tier"]}`, "front"},
+		{"bracket field", "{.items[0][name]}", "nginx"},
+		{"missing field", "{.items[0].missing}", ""},
+		{"null", "{.items[1].risk}", ""},
+		{"number", "{.items[0].risk}", "7.5"},
+		{"bool", "{.items[*].ok}", "true false"},
+		{"array value", "{.items[0].tags}", `["1.25","latest"]`},
+		{"text and literals", `id={.items[0].id}{"\t"}{'x'}`, "id=a\tx"},
+		{"range", `{range .items[*]}{.id}:{.name}{"\n"}{end}`, "a:nginx\nb:redis\n"},
+		{"nested range", `{range .items[*]}{.id}={range .tags[*]}[{@}]{end};{end}`, "a=[1.25][latest];b=;"},
+		{"brace in literal", `{"}"}{.total}`, "}2"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := ParseJSONPath(tt.tmpl)
+			if err != nil {
+				t.Fatalf("ParseJSONPath(%q): %v", tt.tmpl, err)
+			}
+			got, err := j.Execute(doc)
+			if err != nil {
+				t.Fatalf("Execute(%q): %v", tt.tmpl, err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathTopLevelArray(t *testing.T) {
+	doc := decodeJSON(t, `[{"id": "a"}, {"id": "b"}]`)
+	for _, tmpl := range []string{"{.[*].id}", "{[*].id}", "{$[*].id}"} {
+		j, err := ParseJSONPath(tmpl)
+		if err != nil {
+			t.Fatalf("ParseJSONPath(%q): %v", tmpl, err)
+		}
+		got, err := j.Execute(doc)
+		if err != nil {
+			t.Fatalf("Execute(%q): %v", tmpl, err)
+		}
+		if got != "a b" {
+			t.Errorf("Execute(%q) = %q, want %q", tmpl, got, "a b")
+		}
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"filter", "{.items[?(@.id=='a')].name}", "filter"},
+		{"slice", "{.items[0:1].name}", "slice"},
+		{"open slice", "{.items[:1]}", "slice"},
+		{"union", "{.items[0,1].id}", "union"},
+		{"recursive descent", "{..name}", "recursive descent"},
+		{"recursive descent in path", "{.items..name}", "recursive descent"},
+		{"empty brackets", "{.items[]}", "empty '[]'"},
+		{"unclosed bracket", "{.items[0}", "unclosed '['"},
+		{"unclosed brace", "{.items", "unclosed '{'"},
+		{"no leading dot", "{items}", "must start with '.'"},
+		{"trailing dot", "{.items.}", "empty field name"},
+		{"range without end", "{range .items[*]}{.id}", "range without {end}"},
+		{"end without range", "{.id}{end}", "unexpected {end}"},
+		{"invalid literal", `{"a\q"}`, "invalid string literal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONPath(tt.tmpl)
+			if err == nil {
+				t.Fatalf("ParseJSONPath(%q) succeeded, want error containing %q", tt.tmpl, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseJSONPath(%q) error = %q, want it to contain %q", tt.tmpl, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	doc := decodeJSON(t, jsonpathTestDoc)
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{".total", 2.0},
+		{"{.items[0].name}", "nginx"},
+		{".items[*].id", "a"},
+		{".items[1].missing", nil},
+	}
+	for _, tt := range tests {
+		got, err := Lookup(doc, tt.expr)
+		if err != nil {
+			t.Fatalf("Lookup(%q): %v", tt.expr, err)
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+	if _, err := Lookup(doc, ".items[?(@.ok)]"); err == nil {
+		t.Error("Lookup with a filter succeeded, want error")
+	}
+}
@@ -0,0 +1,366 @@
+// Package output renders command results in the formats shared by every kcskit
+// command: table, wide, json, yaml, csv, tsv, jsonpath=..., go-template=... and
+// custom-columns=....
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats lists the accepted --output values, for help texts.
+const Formats = "table|wide|json|yaml|csv|tsv|jsonpath=<template>|go-template=<template>|custom-columns=<HEADER:.path,...>"
+
+// Column is one table column for items of type T.
+type Column[T any] struct {
+	Header string
+	// Wide columns are only shown with -o wide.
+	Wide  bool
+	Value func(T) string
+}
+
+// Options selects how results are printed.
+type Options struct {
+	// Format is one of Formats; empty means "table".
+	Format string
+	// NoHeaders omits the header row of table, wide, csv, tsv and custom-columns output.
+	NoHeaders bool
+	// SortBy is a JSONPath expression evaluated on every item (e.g. ".riskRating").
+	SortBy string
+}
+
+// format splits Format into its kind and argument ("jsonpath={.id}" -> "jsonpath", "{.id}").
+func (o Options) format() (string, string) {
+	kind, arg, _ := strings.Cut(o.Format, "=")
+	if kind == "" {
+		kind = "table"
+	}
+	return kind, arg
+}
+
+// Validate checks the format, its template and the sort expression without printing anything.
+func (o Options) Validate() error {
+	kind, arg := o.format()
+	switch kind {
+	case "table", "wide", "json", "yaml", "csv", "tsv":
+		if arg != "" {
+			return fmt.Errorf("output format %q takes no argument", kind)
+		}
+	case "jsonpath":
+		if _, err := ParseJSONPath(arg); err != nil {
+			return err
+		}
+	case "go-template":
+		if _, err := template.New("output").Parse(arg); err != nil {
+			return err
+		}
+	case "custom-columns":
+		if _, err := parseCustomColumns(arg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown output format %q (want %s)", o.Format, Formats)
+	}
+	if o.SortBy != "" {
+		if _, err := parsePath(strings.Trim(o.SortBy, "{}")); err != nil {
+			return fmt.Errorf("invalid --sort-by: %w", err)
+		}
+	}
+	return nil
+}
+
+// Print writes items to w in the selected format. raw is the API response the
+// items were parsed from; json, yaml, jsonpath and go-template render it (so
+// every API field is available), the other formats render the typed items.
+// When raw is empty the items themselves are rendered. With SortBy set, items
+// are sorted in place.
+func Print[T any](w io.Writer, opts Options, columns []Column[T], items []T, raw []byte) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	kind, arg := opts.format()
+
+	if opts.SortBy != "" {
+		if err := sortItems(items, opts.SortBy); err != nil {
+			return err
+		}
+	}
+
+	switch kind {
+	case "table", "wide":
+		var cols []Column[T]
+		for _, c := range columns {
+			if !c.Wide || kind == "wide" {
+				cols = append(cols, c)
+			}
+		}
+		return writeTable(w, opts.NoHeaders, headers(cols), rows(cols, items))
+	case "csv", "tsv":
+		// machine-readable formats always include the wide columns
+		return writeSeparated(w, kind, opts.NoHeaders, headers(columns), rows(columns, items))
+	case "custom-columns":
+		specs, _ := parseCustomColumns(arg)
+		var hdr []string
+		for _, s := range specs {
+			hdr = append(hdr, s.header)
+		}
+		var out [][]string
+		for _, it := range items {
+			doc, err := toDocument(it)
+			if err != nil {
+				return err
+			}
+			var row []string
+			for _, s := range specs {
+				v, err := s.path.Execute(doc)
+				if err != nil {
+					return err
+				}
+				if v == "" {
+					v = "<none>"
+				}
+				row = append(row, v)
+			}
+			out = append(out, row)
+		}
+		return writeTable(w, opts.NoHeaders, hdr, out)
+	}
+
+	doc, err := document(items, raw, opts.SortBy)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "json":
+		if len(raw) > 0 && opts.SortBy == "" {
+			var pretty bytes.Buffer
+			if err := json.Indent(&pretty, raw, "", "  "); err == nil {
+				_, err = fmt.Fprintln(w, pretty.String())
+				return err
+			}
+		}
+		b, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "yaml":
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case "jsonpath":
+		jp, _ := ParseJSONPath(arg)
+		s, err := jp.Execute(doc)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, s)
+		return err
+	case "go-template":
+		t, _ := template.New("output").Parse(arg)
+		return t.Execute(w, doc)
+	}
+	return nil
+}
+
+func headers[T any](cols []Column[T]) []string {
+	var h []string
+	for _, c := range cols {
+		h = append(h, c.Header)
+	}
+	return h
+}
+
+func rows[T any](cols []Column[T], items []T) [][]string {
+	var out [][]string
+	for _, it := range items {
+		var row []string
+		for _, c := range cols {
+			row = append(row, c.Value(it))
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+// writeTable prints a tabbed table with the layout used across kcskit.
+func writeTable(w io.Writer, noHeaders bool, hdr []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join(hdr, "\t"))
+	}
+	for _, r := range rows {
+		clean := make([]string, len(r))
+		for i, v := range r {
+			clean[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(v)
+		}
+		fmt.Fprintln(tw, strings.Join(clean, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeSeparated prints CSV (RFC 4180) or TSV (tabs and newlines in values replaced by spaces).
+func writeSeparated(w io.Writer, kind string, noHeaders bool, hdr []string, rows [][]string) error {
+	if kind == "csv" {
+		cw := csv.NewWriter(w)
+		if !noHeaders {
+			if err := cw.Write(hdr); err != nil {
+				return err
+			}
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+
+	r := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	write := func(vals []string) error {
+		clean := make([]string, len(vals))
+		for i, v := range vals {
+			clean[i] = r.Replace(v)
+		}
+		_, err := fmt.Fprintln(w, strings.Join(clean, "\t"))
+		return err
+	}
+	if !noHeaders {
+		if err := write(hdr); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type customColumn struct {
+	header string
+	path   *JSONPath
+}
+
+// parseCustomColumns parses "HEADER:.path,HEADER2:.other".
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("custom-columns requires a spec, e.g. custom-columns=ID:.id,NAME:.name")
+	}
+	var cols []customColumn
+	for _, part := range strings.Split(spec, ",") {
+		hdr, expr, ok := strings.Cut(part, ":")
+		if !ok || hdr == "" || expr == "" {
+			return nil, fmt.Errorf("invalid custom-columns entry %q (want HEADER:.path)", part)
+		}
+		jp, err := ParseJSONPath(expr)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, customColumn{header: hdr, path: jp})
+	}
+	return cols, nil
+}
+
+// toDocument converts v to its generic JSON form (maps, slices, float64, ...).
+func toDocument(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// document returns the generic JSON form of raw (or of items when raw is empty),
+// with its item list sorted by sortBy when requested.
+func document[T any](items []T, raw []byte, sortBy string) (any, error) {
+	var doc any
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			// not JSON: render the items instead
+			doc = nil
+		}
+	}
+	if doc == nil {
+		return toDocument(items)
+	}
+	if sortBy != "" {
+		switch t := doc.(type) {
+		case []any:
+			if err := sortItems(t, sortBy); err != nil {
+				return nil, err
+			}
+		case map[string]any:
+			if list, ok := t["items"].([]any); ok {
+				if err := sortItems(list, sortBy); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return doc, nil
+}
+
+// sortItems stably sorts items by the value of the JSONPath expression sortBy.
+// Numbers compare numerically, everything else as text.
+func sortItems[T any](items []T, sortBy string) error {
+	keys := make([]any, len(items))
+	for i, it := range items {
+		doc, err := toDocument(it)
+		if err != nil {
+			return err
+		}
+		if keys[i], err = Lookup(doc, sortBy); err != nil {
+			return err
+		}
+	}
+
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return less(keys[idx[a]], keys[idx[b]])
+	})
+
+	sorted := make([]T, len(items))
+	for i, j := range idx {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+	return nil
+}
+
+func less(a, b any) bool {
+	fa, aok := a.(float64)
+	fb, bok := b.(float64)
+	if aok && bok {
+		return fa < fb
+	}
+	return formatValue(a) < formatValue(b)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/glamour"
+	"golang.org/x/term"
+)
+
+// spinnerFrames are the frames of the "waiting for the first token" spinner.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// AnswerStream prints a streamed Markdown answer as it arrives. Until the first
+// chunk it shows a spinner with the elapsed time on status (only when status
+// is a terminal). The header and chunks are written to out raw; when out is a
+// terminal and they still fit on the screen, Finish replaces them with the
+// glamour-rendered answer.
+type AnswerStream struct {
+	out    *os.File
+	status *os.File
+	tty    bool
+	spins  bool
+	label  string
+
+	mu   sync.Mutex // serializes the spinner with writes to out
+	text strings.Builder
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewAnswerStream starts the spinner (labelled e.g. with the model name) and
+// returns the stream. Call Finish or Close when done.
+func NewAnswerStream(out, status *os.File, label string) *AnswerStream {
+	s := &AnswerStream{
+		out:    out,
+		status: status,
+		tty:    term.IsTerminal(int(out.Fd())),
+		label:  label,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if !term.IsTerminal(int(status.Fd())) {
+		close(s.done)
+		return s
+	}
+	s.spins = true
+	go s.spin()
+	return s
+}
+
+func (s *AnswerStream) spin() {
+	defer close(s.done)
+	start := time.Now()
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		fmt.Fprintf(s.status, "\r%s waiting for %s... %s", spinnerFrames[i%len(spinnerFrames)], s.label, time.Since(start).Round(time.Second))
+		s.mu.Unlock()
+		select {
+		case <-s.stop:
+			s.mu.Lock()
+			fmt.Fprint(s.status, "\r\033[K")
+			s.mu.Unlock()
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// Status replaces the spinner label, e.g. to show progress before the answer starts.
+func (s *AnswerStream) Status(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.spins {
+		fmt.Fprint(s.status, "\r\033[K")
+	}
+	s.label = label
+}
+
+// stopSpinner stops the spinner and clears its line.
+func (s *AnswerStream) stopSpinner() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// WriteHeader prints the report header while the spinner keeps running below it.
+func (s *AnswerStream) WriteHeader(markdown string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.spins {
+		fmt.Fprint(s.status, "\r\033[K")
+	}
+	s.text.WriteString(markdown)
+	fmt.Fprint(s.out, markdown)
+}
+
+// WriteChunk stops the spinner and prints a chunk of the answer.
+func (s *AnswerStream) WriteChunk(chunk string) {
+	s.stopSpinner()
+	s.text.WriteString(chunk)
+	fmt.Fprint(s.out, chunk)
+}
+
+// Close stops the spinner without rendering, e.g. after an error.
+func (s *AnswerStream) Close() {
+	s.stopSpinner()
+	if s.text.Len() > 0 && !strings.HasSuffix(s.text.String(), "\n") {
+		fmt.Fprintln(s.out)
+	}
+}
+
+// Finish completes the stream with the final Markdown answer. On a terminal the
+// streamed text is replaced by markdown rendered when it still fits on the
+// screen; otherwise (not a terminal, or the stream scrolled off the screen,
+// where it cannot be erased) the raw stream is simply terminated, so the
+// answer is never printed twice.
+func (s *AnswerStream) Finish(markdown string) error {
+	s.stopSpinner()
+	width, height, err := term.GetSize(int(s.out.Fd()))
+	if !s.tty || err != nil || screenRows(s.text.String(), width) >= height {
+		if s.text.Len() > 0 && !strings.HasSuffix(s.text.String(), "\n") {
+			fmt.Fprintln(s.out)
+		}
+		return nil
+	}
+
+	rendered, err := glamour.Render(markdown, "dark")
+	if err != nil {
+		s.Close()
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+	// move back to the first streamed row and clear to the end of the screen
+	if rows := screenRows(s.text.String(), width); rows > 1 {
+		fmt.Fprintf(s.out, "\033[%dA", rows-1)
+	}
+	fmt.Fprint(s.out, "\r\033[J")
+	fmt.Fprint(s.out, rendered)
+	return nil
+}
+
+// screenRows returns how many terminal rows text occupies at the given width,
+// counting the row the cursor is left on.
+func screenRows(text string, width int) int {
+	if width <= 0 {
+		width = 80
+	}
+	rows := 0
+	for _, line := range strings.Split(text, "\n") {
+		n := utf8.RuneCountInString(line)
+		if n == 0 {
+			rows++
+			continue
+		}
+		rows += (n + width - 1) / width
+	}
+	return rows
+}
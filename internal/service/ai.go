@@ -0,0 +1,326 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// AIProviders lists the supported values of ai_provider.
+var AIProviders = []string{"ollama", "openai"}
+
+// DefaultContextLength is the context window (in tokens) assumed when neither the
+// configuration nor the backend tells it. It also caps the window of Ollama models
+// without a num_ctx parameter, as Ollama allocates memory for the whole window.
+const DefaultContextLength = 8192
+
+// ErrTokensUnsupported is returned by CountTokens when the backend cannot count tokens.
+var ErrTokensUnsupported = errors.New("token counting is not supported by this provider")
+
+// AIProvider is a chat model backend used for AI reports.
+type AIProvider interface {
+	// Name returns the provider name, one of AIProviders.
+	Name() string
+	// Model returns the configured model name.
+	Model() string
+	// Chat sends messages to the model and streams the answer: onToken (when not
+	// nil) receives every piece of the answer as it arrives.
+	Chat(ctx context.Context, messages []model.Message, onToken func(string)) (model.ChatReply, error)
+	// CountTokens returns the number of tokens of text for the model, or
+	// ErrTokensUnsupported.
+	CountTokens(ctx context.Context, text string) (int, error)
+	// ContextLength returns the context window in tokens the model is used with.
+	// When the backend cannot tell, it returns the error together with
+	// DefaultContextLength, the window the model is then used with.
+	ContextLength(ctx context.Context) (int, error)
+}
+
+// NewAIProvider returns the provider selected by cfg.AiProvider. When it is
+// empty, Ollama is used if configured, then an OpenAI-compatible API.
+func NewAIProvider(cfg model.Config) (AIProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.AiProvider))
+	if name == "" {
+		name = "ollama"
+		if cfg.AiOllamaEndpoint == "" && cfg.AiOpenAIEndpoint != "" {
+			name = "openai"
+		}
+	}
+
+	switch name {
+	case "ollama":
+		if cfg.AiOllamaEndpoint == "" || cfg.AiOllamaModel == "" {
+			return nil, fmt.Errorf("ollama endpoint or model not configured")
+		}
+		key, err := resolveRef(cfg.AiOllamaApiKey, cfg.AiOllamaApiKeyRef, "ollama api key")
+		if err != nil {
+			return nil, err
+		}
+		p := &ollamaProvider{endpoint: strings.TrimSuffix(cfg.AiOllamaEndpoint, "/"), model: cfg.AiOllamaModel, headers: http.Header{}, numCtx: cfg.AiContextLength}
+		if key != "" {
+			p.headers.Set("Authorization", "Bearer "+key)
+		}
+		return p, nil
+	case "openai":
+		if cfg.AiOpenAIEndpoint == "" || cfg.AiOpenAIModel == "" {
+			return nil, fmt.Errorf("openai endpoint or model not configured")
+		}
+		key, err := resolveRef(cfg.AiOpenAIApiKey, cfg.AiOpenAIApiKeyRef, "openai api key")
+		if err != nil {
+			return nil, err
+		}
+		p := &openAIProvider{endpoint: strings.TrimSuffix(cfg.AiOpenAIEndpoint, "/"), model: cfg.AiOpenAIModel, headers: http.Header{}, contextLength: cfg.AiContextLength}
+		if key != "" {
+			header := cfg.AiOpenAIAuthHeader
+			if header == "" || strings.EqualFold(header, "Authorization") {
+				p.headers.Set("Authorization", "Bearer "+key)
+			} else {
+				p.headers.Set(header, key)
+			}
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown ai provider %q (want %s)", cfg.AiProvider, strings.Join(AIProviders, "|"))
+}
+
+// postAI sends a JSON POST request with the provider headers, bound to ctx. Non-2xx
+// responses are returned as errors including the response body.
+func postAI(ctx context.Context, url string, headers http.Header, payload any) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("received HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return resp, nil
+}
+
+// newLineScanner returns a scanner for streamed responses with long lines.
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	return sc
+}
+
+// ollamaProvider talks to Ollama's /api/chat, /api/tokenize and /api/show.
+type ollamaProvider struct {
+	endpoint string
+	model    string
+	headers  http.Header
+	// numCtx is the context window requests run with: the configured one, else
+	// resolved from /api/show by ContextLength. 0 leaves Ollama's default.
+	numCtx int
+}
+
+func (p *ollamaProvider) Name() string  { return "ollama" }
+func (p *ollamaProvider) Model() string { return p.model }
+
+// Chat reads the NDJSON chunks of a streamed /api/chat response.
+func (p *ollamaProvider) Chat(ctx context.Context, messages []model.Message, onToken func(string)) (model.ChatReply, error) {
+	req := model.OllamaRequest{Model: p.model, Messages: messages, Stream: true}
+	if p.numCtx > 0 {
+		req.Options = &model.OllamaOptions{NumCtx: p.numCtx}
+	}
+	resp, err := postAI(ctx, p.endpoint+"/api/chat", p.headers, req)
+	if err != nil {
+		return model.ChatReply{}, fmt.Errorf("failed to send request to ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	reply := model.ChatReply{Model: p.model}
+	var answer strings.Builder
+	sc := newLineScanner(resp.Body)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk model.OllamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return reply, fmt.Errorf("failed to unmarshal ollama response: %w", err)
+		}
+		if chunk.Error != "" {
+			return reply, fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if c := chunk.Message.Content; c != "" {
+			answer.WriteString(c)
+			if onToken != nil {
+				onToken(c)
+			}
+		}
+		if chunk.Done {
+			if chunk.Model != "" {
+				reply.Model = chunk.Model
+			}
+			reply.Content = answer.String()
+			reply.PromptTokens = chunk.PromptEvalCount
+			reply.CompletionTokens = chunk.EvalCount
+			return reply, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return reply, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return reply, fmt.Errorf("ollama response ended before the answer was complete")
+}
+
+func (p *ollamaProvider) CountTokens(ctx context.Context, text string) (int, error) {
+	resp, err := postAI(ctx, p.endpoint+"/api/tokenize", p.headers, model.TokenizeRequest{Model: p.model, Content: text})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request to ollama for tokenization: %w", err)
+	}
+	defer resp.Body.Close()
+	var tr model.TokenizeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal ollama tokenize response: %w", err)
+	}
+	return len(tr.Tokens), nil
+}
+
+// ContextLength returns the configured window, else the num_ctx parameter of
+// the model, else its trained context length capped at DefaultContextLength.
+// Chat requests are sent with the returned window, also with the default one
+// when the model details cannot be read: Ollama's own default is smaller.
+func (p *ollamaProvider) ContextLength(ctx context.Context) (int, error) {
+	if p.numCtx > 0 {
+		return p.numCtx, nil
+	}
+	n, err := p.showContextLength(ctx)
+	if err != nil {
+		n = DefaultContextLength
+	}
+	p.numCtx = n
+	return n, err
+}
+
+// showContextLength reads the context window of the model from /api/show.
+func (p *ollamaProvider) showContextLength(ctx context.Context) (int, error) {
+	resp, err := postAI(ctx, p.endpoint+"/api/show", p.headers, model.OllamaShowRequest{Model: p.model})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request to ollama for model details: %w", err)
+	}
+	defer resp.Body.Close()
+	var show model.OllamaShowResponse
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal ollama show response: %w", err)
+	}
+
+	n := 0
+	for _, line := range strings.Split(show.Parameters, "\n") {
+		if f := strings.Fields(line); len(f) == 2 && f[0] == "num_ctx" {
+			n, _ = strconv.Atoi(f[1])
+		}
+	}
+	if n == 0 {
+		for k, v := range show.ModelInfo {
+			if l, ok := v.(float64); ok && strings.HasSuffix(k, ".context_length") {
+				n = min(int(l), DefaultContextLength)
+			}
+		}
+	}
+	if n <= 0 {
+		n = DefaultContextLength
+	}
+	return n, nil
+}
+
+// openAIProvider talks to an OpenAI-compatible /chat/completions API (OpenAI,
+// vLLM, LM Studio, llama.cpp server, LocalAI). endpoint is the API base URL,
+// e.g. http://localhost:8000/v1.
+type openAIProvider struct {
+	endpoint      string
+	model         string
+	headers       http.Header
+	contextLength int
+}
+
+func (p *openAIProvider) Name() string  { return "openai" }
+func (p *openAIProvider) Model() string { return p.model }
+
+// Chat reads the server-sent events of a streamed chat completion.
+func (p *openAIProvider) Chat(ctx context.Context, messages []model.Message, onToken func(string)) (model.ChatReply, error) {
+	resp, err := postAI(ctx, p.endpoint+"/chat/completions", p.headers, model.OpenAIChatRequest{Model: p.model, Messages: messages, Stream: true})
+	if err != nil {
+		return model.ChatReply{}, fmt.Errorf("failed to send request to openai-compatible api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	reply := model.ChatReply{Model: p.model}
+	var answer strings.Builder
+	sc := newLineScanner(resp.Body)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "data:")
+		if !ok {
+			// blank separators, comments and other SSE fields
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			reply.Content = answer.String()
+			return reply, nil
+		}
+		var chunk model.OpenAIChatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return reply, fmt.Errorf("failed to unmarshal chat completion chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return reply, fmt.Errorf("openai-compatible api: %s", chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			reply.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			reply.PromptTokens = chunk.Usage.PromptTokens
+			reply.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content != "" {
+				answer.WriteString(c.Delta.Content)
+				if onToken != nil {
+					onToken(c.Delta.Content)
+				}
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return reply, fmt.Errorf("failed to read response body: %w", err)
+	}
+	// some servers close the stream without a [DONE] event
+	reply.Content = answer.String()
+	return reply, nil
+}
+
+func (p *openAIProvider) CountTokens(ctx context.Context, text string) (int, error) {
+	return 0, ErrTokensUnsupported
+}
+
+// ContextLength returns the configured window: the API does not report it.
+func (p *openAIProvider) ContextLength(ctx context.Context) (int, error) {
+	if p.contextLength > 0 {
+		return p.contextLength, nil
+	}
+	return DefaultContextLength, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// streamServer answers every request with body.
+func streamServer(t *testing.T, body string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestOllamaChatStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    model.ChatReply
+		chunks  string
+		wantErr string
+	}{
+		{"chunks", `{"message":{"content":"Hel"}}
+{"message":{"content":"lo"}}
+
+{"model":"llama3:8b","message":{"content":"!"},"done":true,"prompt_eval_count":12,"eval_count":3}
+`, model.ChatReply{Model: "llama3:8b", Content: "Hello!", PromptTokens: 12, CompletionTokens: 3}, "Hel|lo|!", ""},
+		{"long line", `{"message":{"content":"` + strings.Repeat("x", 100000) + `"},"done":true}`,
+			model.ChatReply{Model: "m", Content: strings.Repeat("x", 100000)}, strings.Repeat("x", 100000), ""},
+		{"error", `{"message":{"content":"a"}}
+{"error":"model not found"}
+`, model.ChatReply{}, "a", "ollama: model not found"},
+		{"truncated", `{"message":{"content":"a"}}
+`, model.ChatReply{}, "a", "ended before the answer was complete"},
+		{"invalid", "not json\n", model.ChatReply{}, "", "failed to unmarshal ollama response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ollamaProvider{endpoint: streamServer(t, tt.body), model: "m"}
+			var chunks []string
+			got, err := p.Chat(context.Background(), []model.Message{{Role: "user", Content: "hi"}}, func(s string) { chunks = append(chunks, s) })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Chat error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Chat: %v", err)
+			} else if got != tt.want {
+				t.Errorf("Chat = %+v, want %+v", got, tt.want)
+			}
+			if s := strings.Join(chunks, "|"); s != tt.chunks {
+				t.Errorf("streamed %q, want %q", s, tt.chunks)
+			}
+		})
+	}
+}
+
+func TestOpenAIChatStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    model.ChatReply
+		chunks  string
+		wantErr string
+	}{
+		{"events", `: keep-alive
+
+data: {"model":"gpt-4o","choices":[{"delta":{"role":"assistant"}}]}
+
+data: {"choices":[{"delta":{"content":"Hel"}}]}
+event: message
+data:{"choices":[{"delta":{"content":"lo"}}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":9,"completion_tokens":2}}
+
+data: [DONE]
+
+data: {"choices":[{"delta":{"content":"ignored"}}]}
+`, model.ChatReply{Model: "gpt-4o", Content: "Hello", PromptTokens: 9, CompletionTokens: 2}, "Hel|lo", ""},
+		{"without done", `data: {"choices":[{"delta":{"content":"a"}}]}
+`, model.ChatReply{Model: "m", Content: "a"}, "a", ""},
+		{"error", `data: {"error":{"message":"rate limited"}}
+`, model.ChatReply{}, "", "openai-compatible api: rate limited"},
+		{"invalid", "data: {oops}\n", model.ChatReply{}, "", "failed to unmarshal chat completion chunk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &openAIProvider{endpoint: streamServer(t, tt.body), model: "m"}
+			var chunks []string
+			got, err := p.Chat(context.Background(), []model.Message{{Role: "user", Content: "hi"}}, func(s string) { chunks = append(chunks, s) })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Chat error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Chat: %v", err)
+			} else if got != tt.want {
+				t.Errorf("Chat = %+v, want %+v", got, tt.want)
+			}
+			if s := strings.Join(chunks, "|"); s != tt.chunks {
+				t.Errorf("streamed %q, want %q", s, tt.chunks)
+			}
+		})
+	}
+}
+
+func TestPostAIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	p := &openAIProvider{endpoint: srv.URL, model: "m"}
+	_, err := p.Chat(context.Background(), nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "received HTTP 503: model not loaded") {
+		t.Errorf("Chat error = %v, want the status and body", err)
+	}
+}
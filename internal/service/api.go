@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -19,6 +20,7 @@ type APIClient struct {
 	BaseURL *url.URL
 	Token   string
 	HTTP    *http.Client
+	Retry   RetryPolicy
 }
 
 // NewClient creates an APIClient from baseURL and token.
@@ -50,68 +52,57 @@ func NewClient(baseURL, token string, invalidCert bool, caCert string) (*APIClie
 	}
 
 	client := &http.Client{
-		Timeout:   8 * time.Second,
+		Timeout:   DefaultTimeout,
 		Transport: tr,
 	}
 	return &APIClient{
 		BaseURL: u,
 		Token:   token,
 		HTTP:    client,
+		Retry:   DefaultRetryPolicy(),
 	}, nil
 }
 
+// SetTimeout sets the per-attempt HTTP timeout. Zero keeps the current value.
+func (c *APIClient) SetTimeout(d time.Duration) {
+	if d > 0 {
+		c.HTTP.Timeout = d
+	}
+}
+
 // Do performs an HTTP request to actionPath (relative to base URL).
 // method: "GET", "POST", ...
 // actionPath: e.g. "/v1/core-health" or "v1/core-health"
 // rawQuery: optional query string (without leading '?') — can be empty
 // headers: optional additional headers
+// Failed attempts are retried according to c.Retry; ctx cancels the request and any pending retry.
 // Returns status code, response body bytes and error.
-func (c *APIClient) Do(method, actionPath, rawQuery string, headers map[string]string) (int, []byte, error) {
-	actionPath = strings.TrimSpace(actionPath)
-	if i := strings.Index(actionPath, "?"); i != -1 && rawQuery == "" {
-		rawQuery = actionPath[i+1:]
-		actionPath = actionPath[:i]
-	}
-	joined := path.Join(strings.TrimSuffix(c.BaseURL.Path, "/"), strings.TrimPrefix(actionPath, "/"))
-	if !strings.HasPrefix(joined, "/") {
-		joined = "/" + joined
-	}
-	u := c.BaseURL.ResolveReference(&url.URL{Path: joined, RawQuery: rawQuery})
-
-	req, err := http.NewRequest(method, u.String(), nil)
-	if err != nil {
-		return 0, nil, err
-	}
-	req.Header.Set("accept", "application/json")
-	if c.Token != "" {
-		req.Header.Set("Tron-Token", c.Token)
-	}
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := c.HTTP.Do(req)
-	if err != nil {
-		return 0, nil, err
-	}
-	defer resp.Body.Close()
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return resp.StatusCode, nil, err
-	}
-	return resp.StatusCode, b, nil
+func (c *APIClient) Do(ctx context.Context, method, actionPath, rawQuery string, headers map[string]string) (int, []byte, error) {
+	return c.send(ctx, method, c.resolve(actionPath, rawQuery), nil, headers)
 }
 
 // PostJSON marshals payload to JSON and sends it as a POST request to actionPath.
+// POST is only retried when c.Retry.RetryNonIdempotent is set.
 // It returns HTTP status, response body bytes and error.
-func (c *APIClient) PostJSON(actionPath, rawQuery string, payload interface{}, headers map[string]string) (int, []byte, error) {
+func (c *APIClient) PostJSON(ctx context.Context, actionPath, rawQuery string, payload interface{}, headers map[string]string) (int, []byte, error) {
+	return c.SendJSON(ctx, "POST", actionPath, rawQuery, payload, headers)
+}
+
+// SendJSON marshals payload to JSON and sends it with method (POST, PUT, PATCH, ...)
+// to actionPath. Retries follow c.Retry as for Do.
+// It returns HTTP status, response body bytes and error.
+func (c *APIClient) SendJSON(ctx context.Context, method, actionPath, rawQuery string, payload interface{}, headers map[string]string) (int, []byte, error) {
 	// marshal payload
 	b, err := json.Marshal(payload)
 	if err != nil {
 		return 0, nil, err
 	}
+	return c.send(ctx, method, c.resolve(actionPath, rawQuery), b, headers)
+}
 
-	// build URL similar to Do()
+// resolve builds the absolute request URL for actionPath relative to the base URL.
+// A query embedded in actionPath is used when rawQuery is empty.
+func (c *APIClient) resolve(actionPath, rawQuery string) *url.URL {
 	actionPath = strings.TrimSpace(actionPath)
 	if i := strings.Index(actionPath, "?"); i != -1 && rawQuery == "" {
 		rawQuery = actionPath[i+1:]
@@ -121,15 +112,66 @@ func (c *APIClient) PostJSON(actionPath, rawQuery string, payload interface{}, h
 	if !strings.HasPrefix(joined, "/") {
 		joined = "/" + joined
 	}
-	u := c.BaseURL.ResolveReference(&url.URL{Path: joined, RawQuery: rawQuery})
+	return c.BaseURL.ResolveReference(&url.URL{Path: joined, RawQuery: rawQuery})
+}
+
+// send performs the request, retrying network errors and retryable statuses
+// with exponential backoff. payload, when non-nil, is sent as a JSON body.
+// It stops as soon as ctx is done.
+func (c *APIClient) send(ctx context.Context, method string, u *url.URL, payload []byte, headers map[string]string) (int, []byte, error) {
+	policy := c.Retry.normalized()
+	attempts := policy.MaxAttempts
+	if !policy.allowsMethod(method) {
+		attempts = 1
+	}
 
-	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	var (
+		status int
+		body   []byte
+		err    error
+	)
+	for attempt := 1; ; attempt++ {
+		var resp *http.Response
+		status, body, resp, err = c.attempt(ctx, method, u, payload, headers)
+		if attempt >= attempts || ctx.Err() != nil {
+			return status, body, err
+		}
+		if err == nil && !retryableStatus(status) {
+			return status, body, nil
+		}
+
+		wait := policy.backoff(attempt)
+		if d, ok := retryAfter(resp); ok {
+			wait = d
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if err == nil {
+				err = ctx.Err()
+			}
+			return status, body, err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt performs a single HTTP round trip and reads the whole response body.
+func (c *APIClient) attempt(ctx context.Context, method string, u *url.URL, payload []byte, headers map[string]string) (int, []byte, *http.Response, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
 	if err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
 	}
 	// default headers
 	req.Header.Set("accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	if c.Token != "" {
 		req.Header.Set("Tron-Token", c.Token)
 	}
@@ -140,12 +182,12 @@ func (c *APIClient) PostJSON(actionPath, rawQuery string, payload interface{}, h
 
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
 	}
 	defer resp.Body.Close()
-	respBody, err := io.ReadAll(resp.Body)
+	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return resp.StatusCode, nil, err
+		return resp.StatusCode, nil, resp, err
 	}
-	return resp.StatusCode, respBody, nil
+	return resp.StatusCode, b, resp, nil
 }
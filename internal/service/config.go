@@ -1,6 +1,8 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -9,67 +11,374 @@ import (
 	"github.com/arturscheiner/kcskit/internal/model"
 )
 
-// Path returns config file path, ensuring directory exists.
+// DefaultContext is the profile name used for configs written before profiles existed.
+const DefaultContext = "default"
+
+// errNoCurrentContext is returned when no context was requested and none is current.
+var errNoCurrentContext = errors.New("no current context set (use 'kcskit config use-context <name>')")
+
+// configPath overrides the config file location when set (see SetPath).
+var configPath string
+
+// SetPath overrides the config file location (the --config flag). Empty restores the default.
+func SetPath(p string) {
+	configPath = p
+}
+
+// Path returns the config file path: the SetPath override, else $KCSKIT_CONFIG,
+// else $HOME/.kcskit/config. It has no side effects; the directory is created on save.
 func Path() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	if p := os.Getenv("KCSKIT_CONFIG"); p != "" {
+		return p, nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	dir := filepath.Join(home, ".kcskit")
-	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, "config"), nil
+	return filepath.Join(home, ".kcskit", "config"), nil
 }
 
-// Load reads config YAML into model.Config.
-func Load() (model.Config, error) {
-	var cfg model.Config
+// LoadFile reads the config file with all its profiles.
+// A legacy single-profile file is migrated in memory into a "default" context;
+// it is rewritten in the new layout on the next save.
+func LoadFile() (model.ConfigFile, error) {
+	var f model.ConfigFile
 	p, err := Path()
 	if err != nil {
-		return cfg, err
+		return f, err
 	}
 	b, err := os.ReadFile(p)
 	if err != nil {
-		return cfg, err
+		return f, err
+	}
+	return parseFile(b)
+}
+
+// parseFile decodes config YAML, migrating the legacy single-profile layout.
+func parseFile(b []byte) (model.ConfigFile, error) {
+	var f model.ConfigFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return f, err
 	}
-	if err := yaml.Unmarshal(b, &cfg); err != nil {
-		return cfg, err
+	if len(f.Contexts) > 0 {
+		return f, nil
 	}
-	return cfg, nil
+
+	var legacy model.Config
+	if err := yaml.Unmarshal(b, &legacy); err != nil {
+		return f, err
+	}
+	if legacy != (model.Config{}) {
+		f.Contexts = []model.NamedContext{{Name: DefaultContext, Config: legacy}}
+		f.CurrentContext = DefaultContext
+	}
+	return f, nil
 }
 
-// Save merges provided non-empty fields with existing config and writes YAML.
-func Save(toSave model.Config) error {
+// SaveFile writes the config file with all its profiles.
+func SaveFile(f model.ConfigFile) error {
 	p, err := Path()
 	if err != nil {
 		return err
 	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(&f)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, out, 0o600)
+}
+
+// Load reads the current context from the config file.
+func Load() (model.Config, error) {
+	return LoadContext("")
+}
+
+// LoadContext reads the named profile; an empty name selects the current context.
+func LoadContext(name string) (model.Config, error) {
+	cfg, _, err := loadNamed(name)
+	return cfg, err
+}
+
+// loadNamed reads the named profile (empty selects the current context) and
+// returns it with its resolved name.
+func loadNamed(name string) (model.Config, string, error) {
+	f, err := LoadFile()
+	if err != nil {
+		return model.Config{}, "", err
+	}
+	if name == "" {
+		name = f.CurrentContext
+	}
+	if name == "" {
+		return model.Config{}, "", errNoCurrentContext
+	}
+	c := f.Context(name)
+	if c == nil {
+		return model.Config{}, "", fmt.Errorf("context %q not found", name)
+	}
+	return c.Config, name, nil
+}
+
+// Save merges provided non-empty fields with the current context and writes YAML.
+func Save(toSave model.Config) error {
+	return SaveContext("", toSave)
+}
+
+// SaveContext merges provided non-empty fields into the named profile and writes YAML.
+// An empty name selects the current context (or "default" when none is set).
+// The profile is created if it does not exist yet; the first profile becomes current.
+func SaveContext(name string, toSave model.Config) error {
+	f, err := LoadFile()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if name == "" {
+		name = f.CurrentContext
+	}
+	if name == "" {
+		name = DefaultContext
+	}
+	c := f.Context(name)
+	if c == nil {
+		f.Contexts = append(f.Contexts, model.NamedContext{Name: name})
+		c = &f.Contexts[len(f.Contexts)-1]
+	}
+	if f.CurrentContext == "" {
+		f.CurrentContext = name
+	}
+	mergeConfig(&c.Config, toSave)
+	if err := storeToken(name, &c.Config); err != nil {
+		return err
+	}
+	if err := storeAIKeys(name, &c.Config); err != nil {
+		return err
+	}
+
+	return SaveFile(f)
+}
+
+// storeToken moves the token of context name into its secret backend, if one is
+// configured (see storeSecret). A plaintext token given for the read-only exec
+// backend replaces the command.
+func storeToken(name string, c *model.Config) error {
+	if c.Token != "" && (c.SecretBackend == "" || c.SecretBackend == SecretBackendExec) {
+		c.TokenRef = ""
+		c.SecretBackend = ""
+		return nil
+	}
+	if c.SecretBackend == "" {
+		return nil
+	}
+	if !ValidSecretBackend(c.SecretBackend) {
+		return fmt.Errorf("unknown secret backend %q", c.SecretBackend)
+	}
+	return storeSecret(c.SecretBackend, name, &c.Token, &c.TokenRef)
+}
 
-	var existing model.Config
-	if b, err := os.ReadFile(p); err == nil {
-		_ = yaml.Unmarshal(b, &existing)
+// storeAIKeys moves the AI API keys of context name into its secret backend
+// like the token. The exec backend only provides the token, so with it (or
+// without a backend) the keys stay in the config file.
+func storeAIKeys(name string, c *model.Config) error {
+	keys := []struct {
+		locator    string
+		value, ref *string
+	}{
+		{name + ".ai-ollama-api-key", &c.AiOllamaApiKey, &c.AiOllamaApiKeyRef},
+		{name + ".ai-openai-api-key", &c.AiOpenAIApiKey, &c.AiOpenAIApiKeyRef},
+	}
+	for _, k := range keys {
+		if c.SecretBackend == "" || c.SecretBackend == SecretBackendExec {
+			if *k.value != "" {
+				*k.ref = ""
+			}
+			continue
+		}
+		if err := storeSecret(c.SecretBackend, k.locator, k.value, k.ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeSecret writes a plaintext *value to backend under the context's
+// default locator, replacing it with a reference in *ref; a secret held by
+// another backend is migrated.
+func storeSecret(backend, locator string, value, ref *string) error {
+	secret := *value
+	if secret == "" {
+		if *ref == "" || SecretRefBackend(*ref) == backend {
+			return nil
+		}
+		// the secret lives in another backend: read it so it can be migrated
+		old, err := ResolveSecret(*ref)
+		if err != nil {
+			return err
+		}
+		secret = old
+	}
+
+	r := *ref
+	if SecretRefBackend(r) != backend {
+		var err error
+		if r, err = DefaultSecretRef(backend, locator); err != nil {
+			return err
+		}
+	}
+	if err := StoreSecret(r, secret); err != nil {
+		return err
 	}
+	*ref = r
+	*value = ""
+	return nil
+}
 
-	if toSave.Token != "" {
-		existing.Token = toSave.Token
+// mergeConfig copies the non-empty fields of src over dst.
+func mergeConfig(dst *model.Config, src model.Config) {
+	if src.Token != "" {
+		dst.Token = src.Token
+	}
+	if src.Endpoint != "" {
+		dst.Endpoint = src.Endpoint
+	}
+	if src.CaCert != "" {
+		dst.CaCert = src.CaCert
+	}
+	if src.AiOllamaEndpoint != "" {
+		dst.AiOllamaEndpoint = src.AiOllamaEndpoint
+	}
+	if src.AiOllamaModel != "" {
+		dst.AiOllamaModel = src.AiOllamaModel
+	}
+	if src.AiProvider != "" {
+		dst.AiProvider = src.AiProvider
+	}
+	if src.AiOllamaApiKey != "" {
+		dst.AiOllamaApiKey = src.AiOllamaApiKey
+	}
+	if src.AiOpenAIEndpoint != "" {
+		dst.AiOpenAIEndpoint = src.AiOpenAIEndpoint
+	}
+	if src.AiOpenAIModel != "" {
+		dst.AiOpenAIModel = src.AiOpenAIModel
+	}
+	if src.AiOpenAIApiKey != "" {
+		dst.AiOpenAIApiKey = src.AiOpenAIApiKey
+	}
+	if src.AiOpenAIAuthHeader != "" {
+		dst.AiOpenAIAuthHeader = src.AiOpenAIAuthHeader
+	}
+	if src.AiContextLength != 0 {
+		dst.AiContextLength = src.AiContextLength
 	}
-	if toSave.Endpoint != "" {
-		existing.Endpoint = toSave.Endpoint
+	if src.TokenRef != "" {
+		dst.TokenRef = src.TokenRef
+		dst.Token = ""
 	}
-	if toSave.CaCert != "" {
-		existing.CaCert = toSave.CaCert
+	if src.AiOllamaApiKeyRef != "" {
+		dst.AiOllamaApiKeyRef = src.AiOllamaApiKeyRef
+		dst.AiOllamaApiKey = ""
 	}
-	if toSave.AiOllamaEndpoint != "" {
-		existing.AiOllamaEndpoint = toSave.AiOllamaEndpoint
+	if src.AiOpenAIApiKeyRef != "" {
+		dst.AiOpenAIApiKeyRef = src.AiOpenAIApiKeyRef
+		dst.AiOpenAIApiKey = ""
 	}
-	if toSave.AiOllamaModel != "" {
-		existing.AiOllamaModel = toSave.AiOllamaModel
+	if src.SecretBackend != "" {
+		dst.SecretBackend = src.SecretBackend
 	}
+	if src.HTTPTimeout != 0 {
+		dst.HTTPTimeout = src.HTTPTimeout
+	}
+	if src.RetryMaxAttempts != 0 {
+		dst.RetryMaxAttempts = src.RetryMaxAttempts
+	}
+	if src.RetryBackoff != 0 {
+		dst.RetryBackoff = src.RetryBackoff
+	}
+	if src.RetryMaxBackoff != 0 {
+		dst.RetryMaxBackoff = src.RetryMaxBackoff
+	}
+	if src.RetryNonIdempotent != nil {
+		dst.RetryNonIdempotent = src.RetryNonIdempotent
+	}
+}
 
-	out, err := yaml.Marshal(&existing)
+// UseContext makes name the current context.
+func UseContext(name string) error {
+	f, err := LoadFile()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(p, out, 0o600)
+	if f.Context(name) == nil {
+		return fmt.Errorf("context %q not found", name)
+	}
+	f.CurrentContext = name
+	return SaveFile(f)
+}
+
+// RenameContext renames profile oldName to newName, keeping it current if it was.
+func RenameContext(oldName, newName string) error {
+	f, err := LoadFile()
+	if err != nil {
+		return err
+	}
+	c := f.Context(oldName)
+	if c == nil {
+		return fmt.Errorf("context %q not found", oldName)
+	}
+	if f.Context(newName) != nil {
+		return fmt.Errorf("context %q already exists", newName)
+	}
+	c.Name = newName
+	if f.CurrentContext == oldName {
+		f.CurrentContext = newName
+	}
+	return SaveFile(f)
+}
+
+// DeleteContext removes profile name and then the secrets it keeps in a secret
+// backend, so a failure never leaves the context pointing at a deleted secret.
+// Secrets that could not be removed are returned as warnings.
+// Deleting the current context leaves no context selected.
+func DeleteContext(name string) ([]error, error) {
+	f, err := LoadFile()
+	if err != nil {
+		return nil, err
+	}
+	kept := f.Contexts[:0]
+	var deleted *model.NamedContext
+	for _, c := range f.Contexts {
+		if c.Name == name {
+			deleted = &c
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if deleted == nil {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	f.Contexts = kept
+	if f.CurrentContext == name {
+		f.CurrentContext = ""
+	}
+	if err := SaveFile(f); err != nil {
+		return nil, err
+	}
+
+	var warnings []error
+	for _, ref := range []string{deleted.TokenRef, deleted.AiOllamaApiKeyRef, deleted.AiOpenAIApiKeyRef} {
+		if ref == "" || SecretRefBackend(ref) == SecretBackendExec {
+			continue
+		}
+		if err := DeleteSecret(ref); err != nil {
+			warnings = append(warnings, fmt.Errorf("failed to delete stored secret %s: %w", ref, err))
+		}
+	}
+	return warnings, nil
 }
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// configField describes one model.Config setting: its YAML key, the environment
+// variable that overrides it and how to read/write it as a string.
+type configField struct {
+	key string
+	env string
+	get func(c *model.Config) string
+	set func(c *model.Config, v string) error
+}
+
+func durationField(key, env string, ptr func(c *model.Config) *time.Duration) configField {
+	return configField{
+		key: key,
+		env: env,
+		get: func(c *model.Config) string {
+			if *ptr(c) == 0 {
+				return ""
+			}
+			return ptr(c).String()
+		},
+		set: func(c *model.Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return err
+			}
+			*ptr(c) = d
+			return nil
+		},
+	}
+}
+
+func intField(key, env string, ptr func(c *model.Config) *int) configField {
+	return configField{
+		key: key,
+		env: env,
+		get: func(c *model.Config) string {
+			if *ptr(c) == 0 {
+				return ""
+			}
+			return strconv.Itoa(*ptr(c))
+		},
+		set: func(c *model.Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return err
+			}
+			*ptr(c) = n
+			return nil
+		},
+	}
+}
+
+func stringField(key, env string, ptr func(c *model.Config) *string) configField {
+	return configField{
+		key: key,
+		env: env,
+		get: func(c *model.Config) string { return *ptr(c) },
+		set: func(c *model.Config, v string) error { *ptr(c) = v; return nil },
+	}
+}
+
+// configFields lists every setting in display order.
+var configFields = []configField{
+	stringField("token", "KCSKIT_TOKEN", func(c *model.Config) *string { return &c.Token }),
+	stringField("endpoint", "KCSKIT_ENDPOINT", func(c *model.Config) *string { return &c.Endpoint }),
+	stringField("ca_cert", "KCSKIT_CA_CERT", func(c *model.Config) *string { return &c.CaCert }),
+	stringField("ai_ollama_endpoint", "KCSKIT_OLLAMA_ENDPOINT", func(c *model.Config) *string { return &c.AiOllamaEndpoint }),
+	stringField("ai_ollama_model", "KCSKIT_OLLAMA_MODEL", func(c *model.Config) *string { return &c.AiOllamaModel }),
+	stringField("ai_provider", "KCSKIT_AI_PROVIDER", func(c *model.Config) *string { return &c.AiProvider }),
+	stringField("ai_ollama_api_key", "KCSKIT_OLLAMA_API_KEY", func(c *model.Config) *string { return &c.AiOllamaApiKey }),
+	stringField("ai_openai_endpoint", "KCSKIT_OPENAI_ENDPOINT", func(c *model.Config) *string { return &c.AiOpenAIEndpoint }),
+	stringField("ai_openai_model", "KCSKIT_OPENAI_MODEL", func(c *model.Config) *string { return &c.AiOpenAIModel }),
+	stringField("ai_openai_api_key", "KCSKIT_OPENAI_API_KEY", func(c *model.Config) *string { return &c.AiOpenAIApiKey }),
+	stringField("ai_openai_auth_header", "KCSKIT_OPENAI_AUTH_HEADER", func(c *model.Config) *string { return &c.AiOpenAIAuthHeader }),
+	intField("ai_context_length", "KCSKIT_AI_CONTEXT_LENGTH", func(c *model.Config) *int { return &c.AiContextLength }),
+	stringField("token_ref", "KCSKIT_TOKEN_REF", func(c *model.Config) *string { return &c.TokenRef }),
+	stringField("ai_ollama_api_key_ref", "KCSKIT_OLLAMA_API_KEY_REF", func(c *model.Config) *string { return &c.AiOllamaApiKeyRef }),
+	stringField("ai_openai_api_key_ref", "KCSKIT_OPENAI_API_KEY_REF", func(c *model.Config) *string { return &c.AiOpenAIApiKeyRef }),
+	stringField("secret_backend", "", func(c *model.Config) *string { return &c.SecretBackend }),
+	durationField("http_timeout", "KCSKIT_HTTP_TIMEOUT", func(c *model.Config) *time.Duration { return &c.HTTPTimeout }),
+	intField("retry_max_attempts", "KCSKIT_RETRIES", func(c *model.Config) *int { return &c.RetryMaxAttempts }),
+	durationField("retry_backoff", "KCSKIT_RETRY_BACKOFF", func(c *model.Config) *time.Duration { return &c.RetryBackoff }),
+	durationField("retry_max_backoff", "KCSKIT_RETRY_MAX_BACKOFF", func(c *model.Config) *time.Duration { return &c.RetryMaxBackoff }),
+	{
+		key: "retry_non_idempotent",
+		env: "KCSKIT_RETRY_NON_IDEMPOTENT",
+		get: func(c *model.Config) string {
+			if c.RetryNonIdempotent == nil {
+				return ""
+			}
+			return strconv.FormatBool(*c.RetryNonIdempotent)
+		},
+		set: func(c *model.Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			c.RetryNonIdempotent = &b
+			return nil
+		},
+	},
+}
+
+// Resolve returns the effective configuration for the named context (empty selects
+// $KCSKIT_CONTEXT, then the current context) with KCSKIT_* environment variables
+// applied on top of the file. The returned map tells, per YAML key, where each
+// non-empty value came from. A missing config file is not an error when the
+// environment provides settings.
+func Resolve(name string) (model.Config, map[string]string, error) {
+	sources := map[string]string{}
+	if name == "" {
+		name = os.Getenv("KCSKIT_CONTEXT")
+	}
+
+	cfg, ctxName, err := loadNamed(name)
+	if err != nil {
+		if !envConfigured() || name != "" || !(errors.Is(err, os.ErrNotExist) || errors.Is(err, errNoCurrentContext)) {
+			return cfg, nil, err
+		}
+		cfg = model.Config{}
+	} else {
+		p, _ := Path()
+		for _, f := range configFields {
+			if f.get(&cfg) != "" {
+				sources[f.key] = fmt.Sprintf("file %s (context %q)", p, ctxName)
+			}
+		}
+	}
+
+	for _, f := range configFields {
+		if f.env == "" {
+			continue
+		}
+		v, ok := os.LookupEnv(f.env)
+		if !ok || v == "" {
+			continue
+		}
+		if err := f.set(&cfg, v); err != nil {
+			return cfg, nil, fmt.Errorf("invalid %s: %w", f.env, err)
+		}
+		sources[f.key] = "env " + f.env
+	}
+	return cfg, sources, nil
+}
+
+// ResolveToken returns the API token of cfg. A token kept in a secret backend
+// (TokenRef) is only read here, when a request needs it, and only when no
+// plain token overrides it.
+func ResolveToken(cfg model.Config) (string, error) {
+	return resolveRef(cfg.Token, cfg.TokenRef, "token")
+}
+
+// ResolveSecrets reads the token and AI API keys of cfg that are kept in a
+// secret backend into cfg, and records their sources.
+func ResolveSecrets(cfg *model.Config, sources map[string]string) error {
+	secrets := []struct {
+		key, what  string
+		value, ref *string
+	}{
+		{"token", "token", &cfg.Token, &cfg.TokenRef},
+		{"ai_ollama_api_key", "ollama api key", &cfg.AiOllamaApiKey, &cfg.AiOllamaApiKeyRef},
+		{"ai_openai_api_key", "openai api key", &cfg.AiOpenAIApiKey, &cfg.AiOpenAIApiKeyRef},
+	}
+	for _, s := range secrets {
+		if *s.value != "" || *s.ref == "" {
+			continue
+		}
+		v, err := resolveRef(*s.value, *s.ref, s.what)
+		if err != nil {
+			return err
+		}
+		*s.value = v
+		sources[s.key] = "secret " + *s.ref
+	}
+	return nil
+}
+
+// resolveRef returns value, or the secret ref points to when value is empty.
+func resolveRef(value, ref, what string) (string, error) {
+	if value != "" || ref == "" {
+		return value, nil
+	}
+	secret, err := ResolveSecret(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", what, err)
+	}
+	return secret, nil
+}
+
+// Describe lists the effective settings of cfg with their sources, in display order.
+// Unset settings are included with an empty value and source.
+func Describe(cfg model.Config, sources map[string]string) []model.ConfigValue {
+	out := make([]model.ConfigValue, 0, len(configFields))
+	for _, f := range configFields {
+		out = append(out, model.ConfigValue{Key: f.key, Value: f.get(&cfg), Source: sources[f.key]})
+	}
+	return out
+}
+
+// envConfigured reports whether any KCSKIT_* setting is present in the environment.
+func envConfigured() bool {
+	for _, f := range configFields {
+		if f.env != "" && os.Getenv(f.env) != "" {
+			return true
+		}
+	}
+	return false
+}
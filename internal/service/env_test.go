@@ -0,0 +1,106 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// isolateConfig points the config file into a temporary directory and clears
+// every KCSKIT_* variable Resolve reads.
+func isolateConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KCSKIT_CONFIG", path)
+	t.Setenv("KCSKIT_CONTEXT", "")
+	for _, f := range configFields {
+		if f.env != "" {
+			t.Setenv(f.env, "")
+		}
+	}
+	return path
+}
+
+func TestResolve(t *testing.T) {
+	path := isolateConfig(t)
+	err := SaveFile(model.ConfigFile{CurrentContext: "prod", Contexts: []model.NamedContext{
+		{Name: "prod", Config: model.Config{Endpoint: "https://prod.local", Token: "file-token", HTTPTimeout: 5 * time.Second}},
+		{Name: "dev", Config: model.Config{Endpoint: "https://dev.local", Token: "dev-token"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromFile := func(ctx string) string { return "file " + path + " (context \"" + ctx + "\")" }
+
+	tests := []struct {
+		name     string
+		context  string
+		env      map[string]string
+		endpoint string
+		token    string
+		timeout  time.Duration
+		sources  map[string]string
+	}{
+		{"current context", "", nil, "https://prod.local", "file-token", 5 * time.Second, map[string]string{
+			"endpoint": fromFile("prod"), "token": fromFile("prod"), "http_timeout": fromFile("prod"),
+		}},
+		{"env overrides file", "", map[string]string{"KCSKIT_TOKEN": "env-token", "KCSKIT_HTTP_TIMEOUT": "1m"}, "https://prod.local", "env-token", time.Minute, map[string]string{
+			"endpoint": fromFile("prod"), "token": "env KCSKIT_TOKEN", "http_timeout": "env KCSKIT_HTTP_TIMEOUT",
+		}},
+		{"empty env is ignored", "", map[string]string{"KCSKIT_ENDPOINT": ""}, "https://prod.local", "file-token", 5 * time.Second, nil},
+		{"named context", "dev", nil, "https://dev.local", "dev-token", 0, map[string]string{"endpoint": fromFile("dev")}},
+		{"KCSKIT_CONTEXT", "", map[string]string{"KCSKIT_CONTEXT": "dev", "KCSKIT_ENDPOINT": "https://env.local"}, "https://env.local", "dev-token", 0, map[string]string{
+			"endpoint": "env KCSKIT_ENDPOINT", "token": fromFile("dev"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, sources, err := Resolve(tt.context)
+			if err != nil {
+				t.Fatalf("Resolve(%q): %v", tt.context, err)
+			}
+			if cfg.Endpoint != tt.endpoint || cfg.Token != tt.token || cfg.HTTPTimeout != tt.timeout {
+				t.Errorf("Resolve(%q) = endpoint %q, token %q, timeout %v; want %q, %q, %v", tt.context, cfg.Endpoint, cfg.Token, cfg.HTTPTimeout, tt.endpoint, tt.token, tt.timeout)
+			}
+			for k, want := range tt.sources {
+				if sources[k] != want {
+					t.Errorf("source of %s = %q, want %q", k, sources[k], want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	isolateConfig(t)
+
+	// no file and no environment
+	if _, _, err := Resolve(""); !os.IsNotExist(err) {
+		t.Errorf("Resolve without config = %v, want a not-exist error", err)
+	}
+
+	// the environment alone is enough without a file
+	t.Setenv("KCSKIT_ENDPOINT", "https://env.local")
+	t.Setenv("KCSKIT_TOKEN", "t")
+	cfg, sources, err := Resolve("")
+	if err != nil || cfg.Endpoint != "https://env.local" || sources["token"] != "env KCSKIT_TOKEN" {
+		t.Errorf("Resolve from env = %+v, %v, %v", cfg, sources, err)
+	}
+
+	// ... but not for a named context
+	if _, _, err := Resolve("prod"); err == nil {
+		t.Error("Resolve(\"prod\") without config succeeded")
+	}
+
+	t.Setenv("KCSKIT_RETRIES", "three")
+	if _, _, err := Resolve(""); err == nil || !strings.Contains(err.Error(), "invalid KCSKIT_RETRIES") {
+		t.Errorf("Resolve with KCSKIT_RETRIES=three = %v, want an invalid value error", err)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import "fmt"
+
+// PageFetcher fetches a single 1-based page and returns its items, the total
+// number of items reported by the API and the raw response body.
+type PageFetcher[T any] func(page int) ([]T, int, []byte, error)
+
+// Pager iterates over every page of a paginated list endpoint until the
+// reported total is reached or the API returns an empty page.
+type Pager[T any] struct {
+	fetch   PageFetcher[T]
+	page    int
+	fetched int
+	total   int
+	items   []T
+	body    []byte
+	done    bool
+	err     error
+}
+
+// NewPager creates a Pager that starts at page 1 and uses fetch to retrieve pages.
+func NewPager[T any](fetch PageFetcher[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch, total: -1}
+}
+
+// Next fetches the next page. It returns false once all pages were read or an
+// error occurred; check Err afterwards.
+func (p *Pager[T]) Next() bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	if p.total >= 0 && p.fetched >= p.total {
+		p.done = true
+		return false
+	}
+
+	p.page++
+	items, total, body, err := p.fetch(p.page)
+	p.body = body
+	if err != nil {
+		p.err = fmt.Errorf("page %d: %w", p.page, err)
+		return false
+	}
+	p.total = total
+	if len(items) == 0 {
+		p.done = true
+		return false
+	}
+	p.items = items
+	p.fetched += len(items)
+	return true
+}
+
+// Items returns the items of the current page.
+func (p *Pager[T]) Items() []T { return p.items }
+
+// Page returns the number of the current page.
+func (p *Pager[T]) Page() int { return p.page }
+
+// Total returns the total reported by the API (or -1 before the first page).
+func (p *Pager[T]) Total() int { return p.total }
+
+// Body returns the raw body of the last fetched page.
+func (p *Pager[T]) Body() []byte { return p.body }
+
+// Err returns the first error encountered while paging.
+func (p *Pager[T]) Err() error { return p.err }
+
+// FetchAll walks every page with fetch and returns the merged items and the
+// total reported by the API. On error the raw body of the failing page is returned.
+func FetchAll[T any](fetch PageFetcher[T]) ([]T, int, []byte, error) {
+	var all []T
+	p := NewPager(fetch)
+	for p.Next() {
+		all = append(all, p.Items()...)
+	}
+	if err := p.Err(); err != nil {
+		return nil, 0, p.Body(), err
+	}
+	total := p.Total()
+	if total < len(all) {
+		total = len(all)
+	}
+	return all, total, nil, nil
+}
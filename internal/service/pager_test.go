@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// pages returns a PageFetcher serving n items in pages of size, reporting
+// total, and counting its calls.
+func pages(n, size, total int, calls *int) PageFetcher[int] {
+	return func(page int) ([]int, int, []byte, error) {
+		*calls++
+		var items []int
+		for i := (page - 1) * size; i < page*size && i < n; i++ {
+			items = append(items, i)
+		}
+		return items, total, []byte("page"), nil
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		n, size   int
+		total     int
+		wantItems int
+		wantTotal int
+		wantCalls int
+	}{
+		{"stops at total", 6, 3, 6, 6, 6, 2},
+		{"short last page", 7, 3, 7, 7, 7, 3},
+		{"empty", 0, 3, 0, 0, 0, 1},
+		{"empty page before total", 4, 2, 10, 4, 10, 3},
+		{"total below items", 5, 5, 2, 5, 5, 1},
+		{"no total", 5, 2, 0, 2, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			items, total, body, err := FetchAll(pages(tt.n, tt.size, tt.total, &calls))
+			if err != nil {
+				t.Fatalf("FetchAll: %v", err)
+			}
+			if len(items) != tt.wantItems || total != tt.wantTotal || calls != tt.wantCalls {
+				t.Errorf("FetchAll = %d items, total %d, %d calls; want %d, %d, %d", len(items), total, calls, tt.wantItems, tt.wantTotal, tt.wantCalls)
+			}
+			for i, v := range items {
+				if v != i {
+					t.Fatalf("FetchAll items = %v, want them in page order", items)
+				}
+			}
+			if body != nil {
+				t.Errorf("FetchAll body = %q, want nil on success", body)
+			}
+		})
+	}
+}
+
+func TestFetchAllError(t *testing.T) {
+	calls := 0
+	_, _, body, err := FetchAll(func(page int) ([]int, int, []byte, error) {
+		calls++
+		if page == 2 {
+			return nil, 0, []byte("bad gateway"), errors.New("received HTTP 502")
+		}
+		return []int{1, 2}, 10, nil, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "page 2: received HTTP 502") {
+		t.Errorf("FetchAll error = %v, want the failing page", err)
+	}
+	if string(body) != "bad gateway" || calls != 2 {
+		t.Errorf("FetchAll = body %q after %d calls, want the failing page's body after 2", body, calls)
+	}
+}
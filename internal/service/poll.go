@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// Poll calls check immediately and then every interval until it reports done,
+// returns an error, or ctx is done (in which case ctx.Err() is returned).
+func Poll(ctx context.Context, interval time.Duration, check func(ctx context.Context) (bool, error)) error {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		done, err := check(ctx)
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PromptDir returns the directory of the AI prompt templates: the "prompts"
+// directory next to the config file.
+func PromptDir() (string, error) {
+	p, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "prompts"), nil
+}
+
+// PromptPath returns the file of the named prompt template.
+func PromptPath(name string) (string, error) {
+	dir, err := PromptDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".tmpl"), nil
+}
+
+// ReadPrompt reads the named prompt template and returns it with its path. A
+// missing template is reported with an error matching os.ErrNotExist.
+func ReadPrompt(name string) (string, string, error) {
+	p, err := PromptPath(name)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", p, err
+	}
+	return string(b), p, nil
+}
+
+// WritePrompt writes the named prompt template, creating the prompts directory.
+func WritePrompt(name, text string) (string, error) {
+	p, err := PromptPath(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return "", err
+	}
+	return p, os.WriteFile(p, []byte(text), 0o600)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimiter spaces out calls to at most a fixed number per second, shared by
+// any number of goroutines. A nil *RateLimiter does not limit.
+type RateLimiter struct {
+	ticker *time.Ticker
+}
+
+// NewRateLimiter returns a limiter allowing perSecond calls per second, or nil
+// (no limit) when perSecond <= 0 or so large that calls would be less than a
+// nanosecond apart.
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	if !(perSecond > 0) {
+		return nil
+	}
+	interval := float64(time.Second) / perSecond
+	if interval < 1 {
+		return nil
+	}
+	return &RateLimiter{ticker: time.NewTicker(time.Duration(interval))}
+}
+
+// Wait blocks until the next call is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+// Stop releases the limiter.
+func (l *RateLimiter) Stop() {
+	if l != nil {
+		l.ticker.Stop()
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		perSecond float64
+		unlimited bool
+	}{
+		{0, true},
+		{-1, true},
+		{math.NaN(), true},
+		{math.Inf(1), true},
+		{2e9, true},
+		{1e9, false},
+		{5, false},
+		{0.5, false},
+	}
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.perSecond)
+		if (l == nil) != tt.unlimited {
+			t.Errorf("NewRateLimiter(%v) = %v, want unlimited %v", tt.perSecond, l, tt.unlimited)
+		}
+		l.Stop()
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	var unlimited *RateLimiter
+	if err := unlimited.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter Wait = %v", err)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := unlimited.Wait(canceled); err == nil {
+		t.Error("nil limiter Wait ignored a canceled context")
+	}
+	unlimited.Stop()
+
+	l := NewRateLimiter(100)
+	defer l.Stop()
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 25*time.Millisecond {
+		t.Errorf("3 calls at 100/s took %v, want at least 30ms", d)
+	}
+
+	slow := NewRateLimiter(0.5)
+	defer slow.Stop()
+	if err := slow.Wait(canceled); err == nil {
+		t.Error("Wait ignored a canceled context")
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryableStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusNotFound, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusNotImplemented, false},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+		{http.StatusHTTPVersionNotSupported, false},
+		{http.StatusNetworkAuthenticationRequired, false},
+	}
+	for _, tt := range tests {
+		if got := retryableStatus(tt.status); got != tt.want {
+			t.Errorf("retryableStatus(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"seconds", http.StatusTooManyRequests, "3", 3 * time.Second, true},
+		{"service unavailable", http.StatusServiceUnavailable, " 1 ", time.Second, true},
+		{"zero", http.StatusTooManyRequests, "0", 0, true},
+		{"negative", http.StatusTooManyRequests, "-5", 0, true},
+		{"capped", http.StatusTooManyRequests, "86400", maxRetryAfter, true},
+		{"past date", http.StatusTooManyRequests, "Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+		{"absent", http.StatusTooManyRequests, "", 0, false},
+		{"garbage", http.StatusTooManyRequests, "soon", 0, false},
+		{"other status", http.StatusBadGateway, "3", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			got, ok := retryAfter(resp)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("retryAfter(%d, %q) = %v, %v, want %v, %v", tt.status, tt.header, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+	if got, ok := retryAfter(resp); !ok || got <= 25*time.Second || got > 30*time.Second {
+		t.Errorf("retryAfter(future date) = %v, %v, want about 30s", got, ok)
+	}
+	if _, ok := retryAfter(nil); ok {
+		t.Error("retryAfter(nil) reported a delay")
+	}
+}
+
+func TestRetryMethods(t *testing.T) {
+	tests := []struct {
+		method        string
+		nonIdempotent bool
+		want          int
+	}{
+		{http.MethodGet, false, 3},
+		{http.MethodPut, false, 3},
+		{http.MethodDelete, false, 3},
+		{http.MethodPost, false, 1},
+		{http.MethodPatch, false, 1},
+		{http.MethodPost, true, 3},
+	}
+	for _, tt := range tests {
+		attempts := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		c, err := NewClient(srv.URL, "t", false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Retry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, RetryNonIdempotent: tt.nonIdempotent}
+		status, _, err := c.Do(context.Background(), tt.method, "/v1/x", "", nil)
+		srv.Close()
+		if err != nil || status != http.StatusServiceUnavailable {
+			t.Fatalf("%s: Do = %d, %v", tt.method, status, err)
+		}
+		if attempts != tt.want {
+			t.Errorf("%s (non-idempotent retries %v): %d attempts, want %d", tt.method, tt.nonIdempotent, attempts, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}.normalized()
+	for n, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		for range 20 {
+			if d := p.backoff(n + 1); d < max/2 || d > max {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", n+1, d, max/2, max)
+			}
+		}
+	}
+}
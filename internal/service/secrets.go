@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// Secret references stored in the config have the form "<backend>:<locator>", e.g.
+//
+//	keyring:prod                      OS keyring (Secret Service, Keychain, Credential Manager)
+//	age-file:/home/me/.kcskit/secrets/prod.age   passphrase-encrypted age file
+//	exec:pass show kcs/prod           external command printing the secret (read-only)
+
+// Secret backend names.
+const (
+	SecretBackendKeyring = "keyring"
+	SecretBackendAgeFile = "age-file"
+	SecretBackendExec    = "exec"
+)
+
+// keyringService is the service name used for OS keyring entries.
+const keyringService = "kcskit"
+
+// SecretBackend stores and retrieves secrets addressed by a backend-specific locator.
+type SecretBackend interface {
+	Get(locator string) (string, error)
+	Set(locator, secret string) error
+	Delete(locator string) error
+}
+
+// secretBackends holds the available backends by name.
+var secretBackends = map[string]SecretBackend{
+	SecretBackendKeyring: keyringBackend{},
+	SecretBackendAgeFile: ageFileBackend{},
+	SecretBackendExec:    execBackend{},
+}
+
+// PromptPassphrase asks for the passphrase protecting age-file secrets. The default
+// implementation uses $KCSKIT_SECRET_PASSPHRASE or prompts on the terminal.
+var PromptPassphrase = func(prompt string) (string, error) {
+	if p := os.Getenv("KCSKIT_SECRET_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("passphrase required: set KCSKIT_SECRET_PASSPHRASE or run in a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// splitSecretRef splits a reference into its backend and locator.
+func splitSecretRef(ref string) (SecretBackend, string, string, error) {
+	name, locator, ok := strings.Cut(ref, ":")
+	if !ok || locator == "" {
+		return nil, "", "", fmt.Errorf("invalid secret reference %q (want <backend>:<locator>)", ref)
+	}
+	b, ok := secretBackends[name]
+	if !ok {
+		return nil, "", "", fmt.Errorf("unknown secret backend %q", name)
+	}
+	return b, name, locator, nil
+}
+
+// SecretRefBackend returns the backend name of a secret reference ("" if ref is empty or invalid).
+func SecretRefBackend(ref string) string {
+	_, name, _, err := splitSecretRef(ref)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// ValidSecretBackend reports whether name is a known backend.
+func ValidSecretBackend(name string) bool {
+	_, ok := secretBackends[name]
+	return ok
+}
+
+// resolvedSecrets caches the secrets read by ResolveSecret, so a backend
+// (passphrase prompt, external command) runs at most once per invocation.
+var (
+	resolvedMu      sync.Mutex
+	resolvedSecrets = map[string]string{}
+)
+
+// ResolveSecret reads the secret addressed by ref.
+func ResolveSecret(ref string) (string, error) {
+	resolvedMu.Lock()
+	defer resolvedMu.Unlock()
+	if s, ok := resolvedSecrets[ref]; ok {
+		return s, nil
+	}
+	b, name, locator, err := splitSecretRef(ref)
+	if err != nil {
+		return "", err
+	}
+	s, err := b.Get(locator)
+	if err != nil {
+		return "", fmt.Errorf("%s secret: %w", name, err)
+	}
+	resolvedSecrets[ref] = s
+	return s, nil
+}
+
+// forgetSecret drops ref from the ResolveSecret cache.
+func forgetSecret(ref string) {
+	resolvedMu.Lock()
+	delete(resolvedSecrets, ref)
+	resolvedMu.Unlock()
+}
+
+// StoreSecret writes secret to the location addressed by ref.
+func StoreSecret(ref, secret string) error {
+	b, name, locator, err := splitSecretRef(ref)
+	if err != nil {
+		return err
+	}
+	forgetSecret(ref)
+	if err := b.Set(locator, secret); err != nil {
+		return fmt.Errorf("%s secret: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteSecret removes the secret addressed by ref.
+func DeleteSecret(ref string) error {
+	b, _, locator, err := splitSecretRef(ref)
+	if err != nil {
+		return err
+	}
+	forgetSecret(ref)
+	return b.Delete(locator)
+}
+
+// DefaultSecretRef returns the reference used to store the token of context
+// contextName in backend.
+func DefaultSecretRef(backend, contextName string) (string, error) {
+	switch backend {
+	case SecretBackendKeyring:
+		return SecretBackendKeyring + ":" + contextName, nil
+	case SecretBackendAgeFile:
+		p, err := Path()
+		if err != nil {
+			return "", err
+		}
+		return SecretBackendAgeFile + ":" + filepath.Join(filepath.Dir(p), "secrets", contextName+".age"), nil
+	case SecretBackendExec:
+		return "", errors.New("the exec backend is read-only; set the command with --token-command")
+	}
+	return "", fmt.Errorf("unknown secret backend %q", backend)
+}
+
+// keyringBackend stores secrets in the OS keyring under the "kcskit" service.
+type keyringBackend struct{}
+
+func (keyringBackend) Get(locator string) (string, error) {
+	return keyring.Get(keyringService, locator)
+}
+
+func (keyringBackend) Set(locator, secret string) error {
+	return keyring.Set(keyringService, locator, secret)
+}
+
+func (keyringBackend) Delete(locator string) error {
+	err := keyring.Delete(keyringService, locator)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ageFileBackend stores secrets in armored age files encrypted with a passphrase.
+type ageFileBackend struct{}
+
+// agePassphrase caches the last passphrase that opened or wrote an age file,
+// so a context whose token and AI keys are all age files asks for it once per
+// invocation. A file with another passphrase still prompts for its own.
+var (
+	agePassMu     sync.Mutex
+	agePassphrase string
+)
+
+func (ageFileBackend) Get(locator string) (string, error) {
+	data, err := os.ReadFile(locator)
+	if err != nil {
+		return "", err
+	}
+
+	agePassMu.Lock()
+	defer agePassMu.Unlock()
+	if agePassphrase != "" {
+		if s, err := ageDecrypt(data, agePassphrase); err == nil {
+			return s, nil
+		}
+	}
+	pass, err := PromptPassphrase(fmt.Sprintf("Passphrase for %s: ", locator))
+	if err != nil {
+		return "", err
+	}
+	s, err := ageDecrypt(data, pass)
+	if err != nil {
+		return "", err
+	}
+	agePassphrase = pass
+	return s, nil
+}
+
+// ageDecrypt decrypts an armored age file with a passphrase.
+func ageDecrypt(data []byte, pass string) (string, error) {
+	id, err := age.NewScryptIdentity(pass)
+	if err != nil {
+		return "", err
+	}
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(data)), id)
+	if err != nil {
+		return "", err
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (ageFileBackend) Set(locator, secret string) error {
+	agePassMu.Lock()
+	defer agePassMu.Unlock()
+	pass := agePassphrase
+	if pass == "" {
+		var err error
+		pass, err = PromptPassphrase(fmt.Sprintf("New passphrase for %s: ", locator))
+		if err != nil {
+			return err
+		}
+		if pass == "" {
+			return errors.New("empty passphrase")
+		}
+	}
+	rcpt, err := age.NewScryptRecipient(pass)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, rcpt)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, secret); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := aw.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(locator), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(locator, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	agePassphrase = pass
+	return nil
+}
+
+func (ageFileBackend) Delete(locator string) error {
+	err := os.Remove(locator)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// execBackend runs a shell command (e.g. "pass show kcs/prod") and uses the first
+// line of its output as the secret. It cannot store secrets.
+type execBackend struct{}
+
+func (execBackend) Get(locator string) (string, error) {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", locator)
+	} else {
+		c = exec.Command("sh", "-c", locator)
+	}
+	c.Stdin = os.Stdin
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", locator, err)
+	}
+	line, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+func (execBackend) Set(locator, secret string) error {
+	return errors.New("the exec backend is read-only")
+}
+
+func (execBackend) Delete(locator string) error {
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// stubPassphrase replaces PromptPassphrase with one returning pass and counting
+// the prompts, and clears the passphrase and secret caches.
+func stubPassphrase(t *testing.T, pass string) *int {
+	t.Helper()
+	prompts := 0
+	orig := PromptPassphrase
+	PromptPassphrase = func(string) (string, error) {
+		prompts++
+		return pass, nil
+	}
+	reset := func() {
+		agePassphrase = ""
+		resolvedMu.Lock()
+		resolvedSecrets = map[string]string{}
+		resolvedMu.Unlock()
+	}
+	reset()
+	t.Cleanup(func() {
+		PromptPassphrase = orig
+		reset()
+	})
+	return &prompts
+}
+
+func TestAgeFilePassphrasePrompt(t *testing.T) {
+	dir := t.TempDir()
+	refs := []string{
+		"age-file:" + filepath.Join(dir, "prod.token"),
+		"age-file:" + filepath.Join(dir, "prod.ai-openai-api-key"),
+	}
+	prompts := stubPassphrase(t, "correct horse")
+	for _, ref := range refs {
+		if err := StoreSecret(ref, "secret of "+ref); err != nil {
+			t.Fatalf("StoreSecret(%q): %v", ref, err)
+		}
+	}
+	if *prompts != 1 {
+		t.Errorf("storing %d secrets prompted %d times, want 1", len(refs), *prompts)
+	}
+
+	prompts = stubPassphrase(t, "correct horse")
+	for _, ref := range refs {
+		got, err := ResolveSecret(ref)
+		if err != nil {
+			t.Fatalf("ResolveSecret(%q): %v", ref, err)
+		}
+		if got != "secret of "+ref {
+			t.Errorf("ResolveSecret(%q) = %q, want %q", ref, got, "secret of "+ref)
+		}
+	}
+	if *prompts != 1 {
+		t.Errorf("reading %d secrets prompted %d times, want 1", len(refs), *prompts)
+	}
+
+	stubPassphrase(t, "wrong")
+	if _, err := ResolveSecret(refs[0]); err == nil {
+		t.Error("ResolveSecret with a wrong passphrase succeeded")
+	}
+}
+
+func TestDeleteContext(t *testing.T) {
+	dir := t.TempDir()
+	SetPath(filepath.Join(dir, "config"))
+	t.Cleanup(func() { SetPath("") })
+	stubPassphrase(t, "pass")
+
+	ref := "age-file:" + filepath.Join(dir, "secrets", "prod.token")
+	if err := StoreSecret(ref, "t0ken"); err != nil {
+		t.Fatalf("StoreSecret: %v", err)
+	}
+	f := model.ConfigFile{CurrentContext: "prod", Contexts: []model.NamedContext{
+		{Name: "prod", Config: model.Config{Endpoint: "https://kcs.local", TokenRef: ref}},
+		{Name: "dev", Config: model.Config{Endpoint: "https://dev.local", Token: "t"}},
+	}}
+	if err := SaveFile(f); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	// a read-only config file makes the save fail: the secret must survive
+	path := filepath.Join(dir, "config")
+	if err := os.Chmod(path, 0o400); err != nil {
+		t.Fatal(err)
+	}
+	if w, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+		w.Close()
+		t.Log("file permissions are not enforced (running as root?): skipping the failed save")
+	} else {
+		if _, err := DeleteContext("prod"); err == nil {
+			t.Fatal("DeleteContext succeeded with a read-only config file")
+		}
+		if _, err := os.Stat(strings.TrimPrefix(ref, "age-file:")); err != nil {
+			t.Errorf("secret removed although the context was kept: %v", err)
+		}
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings, err := DeleteContext("prod")
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("DeleteContext = %v, %v", warnings, err)
+	}
+	if _, err := os.Stat(strings.TrimPrefix(ref, "age-file:")); !os.IsNotExist(err) {
+		t.Errorf("secret not removed: %v", err)
+	}
+	got, err := LoadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentContext != "" || len(got.Contexts) != 1 || got.Contexts[0].Name != "dev" {
+		t.Errorf("config after delete = %+v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/arturscheiner/kcskit/internal/model"
+)
+
+// TranscriptDir returns the directory AI chat transcripts are saved to: the
+// "transcripts" directory next to the config file.
+func TranscriptDir() (string, error) {
+	p, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "transcripts"), nil
+}
+
+// NewTranscriptPath reserves a new file for a transcript created at t.Created:
+// chat-<time>.json, or chat-<time>-<n>.json when sessions started in the same
+// second. The file is created empty (O_EXCL), so concurrent sessions never
+// share a file.
+func NewTranscriptPath(t model.ChatTranscript) (string, error) {
+	dir, err := TranscriptDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	base := "chat-" + t.Created.Format("20060102-150405")
+	for n := 1; ; n++ {
+		name := base + ".json"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.json", base, n)
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
+
+// SaveTranscript writes t as indented JSON to path, creating its directory.
+// Transcripts contain KCS data, so they are only readable by the user.
+func SaveTranscript(path string, t model.ChatTranscript) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// LoadTranscript reads a transcript saved by SaveTranscript.
+func LoadTranscript(path string) (model.ChatTranscript, error) {
+	var t model.ChatTranscript
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, fmt.Errorf("invalid transcript %s: %w", path, err)
+	}
+	return t, nil
+}